@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BlockNode is one layer of the storage stack: a disk, a partition, or a
+// virtual device (md, dm-crypt, LVM, ...) built on top of its parent
+type BlockNode struct {
+	Name     string // kernel name, e.g. sda2 or dm-0
+	Label    string // friendlier name where one exists, e.g. the dm name
+	Dev      string // major:minor
+	Type     string
+	Bytes    uint64
+	Slaves   []string
+	Children []*BlockNode
+}
+
+// BuildBlockStack walks partitions and holders from dev upwards, so the
+// result reads disk → partition → md → crypt → LV
+func BuildBlockStack(dev string) *BlockNode {
+	return buildBlockNode(dev, map[string]bool{})
+}
+
+func buildBlockNode(name string, visited map[string]bool) *BlockNode {
+	visited[name] = true
+	base := "/sys/class/block/" + name + "/"
+	node := &BlockNode{Name: name, Label: name}
+	node.Type, node.Label = blockType(name)
+	if sectors, err := strconv.ParseUint(readSysString(base+"size"), 10, 64); err == nil {
+		node.Bytes = sectors * 512
+	}
+	node.Dev = readSysString(base + "dev")
+	node.Slaves = listDir(base + "slaves")
+
+	var children []string
+	if node.Type != "part" {
+		// md arrays and whole disks can both be partitioned
+		children = append(children, GetPartitions(name)...)
+	}
+	children = append(children, listDir(base+"holders")...)
+	for _, c := range children {
+		// A device held by several members (md, LVM spanning PVs) is only expanded once
+		if visited[c] {
+			continue
+		}
+		node.Children = append(node.Children, buildBlockNode(c, visited))
+	}
+	return node
+}
+
+// blockType labels a device from its sysfs attributes and returns its display name
+func blockType(name string) (string, string) {
+	base := "/sys/class/block/" + name + "/"
+	if pathExists(base + "partition") {
+		return "part", name
+	}
+	if level := readSysString(base + "md/level"); level != "" {
+		return level, name
+	}
+	if pathExists(base + "dm") {
+		dmName := readSysString(base + "dm/name")
+		if dmName == "" {
+			dmName = name
+		}
+		uuid := readSysString(base + "dm/uuid")
+		switch {
+		case strings.HasPrefix(uuid, "CRYPT-LUKS"):
+			// CRYPT-LUKS2-<uuid>-<name>
+			return "crypt (" + strings.SplitN(strings.TrimPrefix(uuid, "CRYPT-"), "-", 2)[0] + ")", dmName
+		case strings.HasPrefix(uuid, "CRYPT-"):
+			return "crypt", dmName
+		case strings.HasPrefix(uuid, "LVM-"):
+			return "lvm", dmName
+		case strings.HasPrefix(uuid, "mpath-"):
+			return "mpath", dmName
+		case strings.HasPrefix(uuid, "part"):
+			return "part", dmName
+		}
+		return "dm", dmName
+	}
+	return "disk", name
+}
+
+func listDir(path string) []string {
+	var names []string
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return names
+	}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
@@ -13,9 +13,16 @@ import (
 
 type Config struct {
 	FanLabels map[string]string `yaml:"fan_labels"`
+	// "iec" (GiB, 1024-based, the default) or "si" (GB, 1000-based)
+	SizeUnits string `yaml:"size_units"`
+	// Restore governor/EPP/boost changed from the CPU card when exiting (default true)
+	RevertCPUSettings *bool `yaml:"revert_cpu_settings_on_exit"`
 }
 
-func loadConfig() map[string]string {
+// Set from the config at startup; read by formatBytes
+var useSIUnits bool
+
+func loadConfig() Config {
 	homeDir := ""
 	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
 		usr, err := user.Lookup(sudoUser)
@@ -26,7 +33,7 @@ func loadConfig() map[string]string {
 	if homeDir == "" {
 		usr, err := user.Current()
 		if err != nil {
-			return map[string]string{}
+			return Config{}
 		}
 		homeDir = usr.HomeDir
 	}
@@ -34,13 +41,31 @@ func loadConfig() map[string]string {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		fmt.Printf("Error reading config file: %v\n", err)
-		return map[string]string{}
+		return Config{}
 	}
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		return map[string]string{}
+		return Config{}
+	}
+	return cfg
+}
+
+// formatBytes renders a size in the unit system picked by size_units
+func formatBytes(b uint64) string {
+	base, units := 1024.0, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	if useSIUnits {
+		base, units = 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB"}
+	}
+	v := float64(b)
+	i := 0
+	for v >= base && i < len(units)-1 {
+		v /= base
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d %s", b, units[0])
 	}
-	return cfg.FanLabels
+	return fmt.Sprintf("%.2f %s", v, units[i])
 }
 
 func normalizeFanKey(s string) string {
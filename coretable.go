@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+const (
+	coreColumnCore = iota
+	coreColumnFreq
+	coreColumnUtil
+	coreColumnTemp
+)
+
+var coreColumnTitles = []string{"Core", "MHz", "Util", "Temp"}
+
+// CoreStats joins frequency, utilization and temperature for one physical core
+type CoreStats struct {
+	Socket  int
+	CoreID  int
+	Kind    string
+	Threads []int
+	MHz     float64 // highest current frequency among the core's threads
+	MaxMHz  float64 // cpuinfo_max_freq, 0 without cpufreq
+	Util    float64 // average busy percent over the core's threads
+	// Busy percent per logical CPU, parallel to Threads; -1 when not sampled yet
+	ThreadUtil []float64
+	Temp       float64
+	HasTemp    bool
+}
+
+// CoreTableSort survives refreshes so the table keeps the user's ordering
+type CoreTableSort struct {
+	Column int
+	Desc   bool
+}
+
+// BuildCoreStats maps every source onto the sysfs topology so the core index
+// is the same for all columns
+func BuildCoreStats(topo CPUTopology, freqs []CPUFreq, threadMHz map[int]float64, usage map[int]CPUUsage, temps map[CoreKey]float64) []CoreStats {
+	freqByCPU := map[int]float64{}
+	maxByCPU := map[int]float64{}
+	for _, f := range freqs {
+		freqByCPU[f.CPU] = float64(f.CurKHz) / 1000
+		maxByCPU[f.CPU] = float64(f.MaxKHz) / 1000
+	}
+	var stats []CoreStats
+	for _, s := range topo.Sockets {
+		for _, c := range s.Cores {
+			cs := CoreStats{Socket: s.ID, CoreID: c.ID, Kind: c.Kind, Threads: c.Threads}
+			utilSamples := 0
+			for _, th := range c.Threads {
+				mhz, ok := freqByCPU[th]
+				if !ok {
+					mhz = threadMHz[th]
+				}
+				if mhz > cs.MHz {
+					cs.MHz = mhz
+				}
+				if maxByCPU[th] > cs.MaxMHz {
+					cs.MaxMHz = maxByCPU[th]
+				}
+				threadUtil := -1.0
+				if u, ok := usage[th]; ok {
+					threadUtil = u.Busy()
+					cs.Util += threadUtil
+					utilSamples++
+				}
+				cs.ThreadUtil = append(cs.ThreadUtil, threadUtil)
+			}
+			if utilSamples > 0 {
+				cs.Util /= float64(utilSamples)
+			}
+			cs.Temp, cs.HasTemp = temps[CoreKey{Package: s.ID, Core: c.ID}]
+			stats = append(stats, cs)
+		}
+	}
+	return stats
+}
+
+func sortCoreStats(stats []CoreStats, order CoreTableSort) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		var less bool
+		switch order.Column {
+		case coreColumnFreq:
+			less = a.MHz < b.MHz
+		case coreColumnUtil:
+			less = a.Util < b.Util
+		case coreColumnTemp:
+			less = a.Temp < b.Temp
+		default:
+			if a.Socket != b.Socket {
+				less = a.Socket < b.Socket
+			} else {
+				less = a.Threads[0] < b.Threads[0]
+			}
+		}
+		if order.Desc {
+			return !less
+		}
+		return less
+	})
+}
+
+// MakeCoreTable renders the per-core table; clicking a header sorts by that
+// column, clicking it again flips the direction
+func MakeCoreTable(stats []CoreStats, order *CoreTableSort, tempThreshold float64, onSort func()) fyne.CanvasObject {
+	sortCoreStats(stats, *order)
+
+	cells := []fyne.CanvasObject{}
+	for i, title := range coreColumnTitles {
+		col := i
+		if col == order.Column {
+			if order.Desc {
+				title += " ▼"
+			} else {
+				title += " ▲"
+			}
+		}
+		cells = append(cells, widget.NewButton(title, func() {
+			if order.Column == col {
+				order.Desc = !order.Desc
+			} else {
+				// Hottest/busiest first is the useful default for the value columns
+				order.Column, order.Desc = col, col != coreColumnCore
+			}
+			// Rebuilding the table from inside the tap would replace this button mid-event
+			go fyne.Do(onSort)
+		}))
+	}
+
+	for _, cs := range stats {
+		name := fmt.Sprintf("%d", cs.CoreID)
+		if cs.Kind != "" {
+			name = cs.Kind + name
+		}
+		if cs.Socket > 0 {
+			name = fmt.Sprintf("S%d %s", cs.Socket, name)
+		}
+		name += fmt.Sprintf(" (CPU %s)", joinInts(cs.Threads))
+		cells = append(cells, widget.NewLabel(name), coreFreqCell(cs), coreUtilCell(cs))
+		if cs.HasTemp {
+			cells = append(cells, container.NewCenter(tempText(cs.Temp, tempThreshold)))
+		} else {
+			cells = append(cells, widget.NewLabel("N/A"))
+		}
+	}
+	return container.NewGridWithColumns(len(coreColumnTitles), cells...)
+}
+
+// coreFreqCell draws the current frequency as a bar against the core's max
+func coreFreqCell(cs CoreStats) fyne.CanvasObject {
+	label := widget.NewLabel(fmt.Sprintf("%.0f", cs.MHz))
+	if cs.MaxMHz <= 0 {
+		return label
+	}
+	fraction := cs.MHz / cs.MaxMHz
+	return container.NewHBox(
+		container.NewCenter(makeBar(fraction, color.RGBA{40, 120, 220, 255})),
+		widget.NewLabel(fmt.Sprintf("%.0f / %.0f", cs.MHz, cs.MaxMHz)),
+	)
+}
+
+// coreUtilCell shows one bar per logical CPU so a single pegged SMT sibling
+// isn't hidden in the core average
+func coreUtilCell(cs CoreStats) fyne.CanvasObject {
+	var rows []fyne.CanvasObject
+	for i, util := range cs.ThreadUtil {
+		if util < 0 {
+			continue
+		}
+		rows = append(rows, container.NewHBox(
+			container.NewCenter(makeBar(util/100, fullnessColor(util/100))),
+			widget.NewLabel(fmt.Sprintf("CPU %d %.0f%%", cs.Threads[i], util)),
+		))
+	}
+	if len(rows) == 0 {
+		return widget.NewLabel("N/A")
+	}
+	return container.NewVBox(rows...)
+}
@@ -3,110 +3,151 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 )
 
-func GetCPUInfo() (model string, mhz string, cores string, threads string) {
-	data, err := os.ReadFile("/proc/cpuinfo")
-	if err != nil {
-		return model, mhz, "N/A", "N/A"
+type CPUInfo struct {
+	Model   string
+	MHz     string // comma separated, one entry per core
+	Cores   string
+	Threads string
+	// Identification, as reported for the first processor
+	Vendor    string
+	Family    string
+	ModelID   string
+	Stepping  string
+	Microcode string
+	Flags     []string
+	// "cpu MHz" per logical CPU, used when there is no cpufreq driver
+	ThreadMHz map[int]float64
+}
+
+type CPUVulnerability struct {
+	Name   string
+	Status string
+}
+
+// Instruction set and virtualization flags worth calling out (x86 flags, then arm64 Features)
+var notableCPUFlags = []string{
+	"aes", "vaes", "sha_ni", "avx", "avx2", "avx512f", "avx512bw", "avx512vl", "avx512_vnni", "avx512_bf16",
+	"amx_tile", "vmx", "svm", "hypervisor", "sha1", "sha2", "sha512", "asimd", "sve", "sve2",
+}
+
+// GetCPUInfo reads the model, identification, flags and per-core MHz from
+// /proc/cpuinfo, using the sysfs topology to pick one thread per core
+func GetCPUInfo(topo CPUTopology) CPUInfo {
+	procs := parseCPUInfo()
+	if len(procs) == 0 {
+		return CPUInfo{Model: "N/A", Cores: "N/A", Threads: "N/A"}
 	}
-	blocks := strings.Split(string(data), "\n\n")
-	model = "N/A"
-	coreSpeedMap := make(map[string]string)
-	coreIDSet := make(map[string]struct{})
-	threadCount := 0
-	physIDPresent := false
-	for _, block := range blocks {
-		physID := ""
-		coreID := ""
-		speed := ""
-		lines := strings.Split(block, "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "physical id") {
-				physID = strings.TrimSpace(strings.Split(line, ":")[1])
-				physIDPresent = true
-			}
-			if strings.HasPrefix(line, "core id") {
-				coreID = strings.TrimSpace(strings.Split(line, ":")[1])
-			}
-			if strings.HasPrefix(line, "processor") {
-				threadCount++
-			}
-			if strings.HasPrefix(line, "model name") {
-				model = strings.TrimSpace(strings.Split(line, ":")[1])
-			}
-			if strings.HasPrefix(line, "cpu MHz") {
-				speed = strings.TrimSpace(strings.Split(line, ":")[1])
-			}
+	info := CPUInfo{
+		Model:     "N/A",
+		Vendor:    procs[0]["vendor_id"],
+		Family:    procs[0]["cpu family"],
+		ModelID:   procs[0]["model"],
+		Stepping:  procs[0]["stepping"],
+		Microcode: procs[0]["microcode"],
+	}
+	if info.Microcode == "" {
+		info.Microcode = readSysString("/sys/devices/system/cpu/cpu0/microcode/version")
+	}
+	flags := procs[0]["flags"]
+	if flags == "" {
+		flags = procs[0]["Features"]
+	}
+	flagSet := map[string]bool{}
+	for _, f := range strings.Fields(flags) {
+		flagSet[f] = true
+	}
+	for _, f := range notableCPUFlags {
+		if flagSet[f] {
+			info.Flags = append(info.Flags, f)
 		}
-		if physID != "" && coreID != "" && speed != "" {
-			key := physID + "-" + coreID
-			coreSpeedMap[key] = speed
+	}
+
+	speedByCPU := map[int]string{}
+	info.ThreadMHz = map[int]float64{}
+	for _, p := range procs {
+		if m := p["model name"]; m != "" {
+			info.Model = m
 		}
-		if coreID != "" {
-			coreIDSet[coreID] = struct{}{}
+		if n, err := strconv.Atoi(p["processor"]); err == nil {
+			speedByCPU[n] = p["cpu MHz"]
+			if mhz, err := strconv.ParseFloat(p["cpu MHz"], 64); err == nil {
+				info.ThreadMHz[n] = mhz
+			}
 		}
 	}
+
 	var speeds []string
-	var coreCount int
-	if physIDPresent {
-		// Sort keys for display
-		type coreKey struct{ phys, core int }
-		var keys []coreKey
-		for k := range coreSpeedMap {
-			var phys, core int
-			fmt.Sscanf(k, "%d-%d", &phys, &core)
-			keys = append(keys, coreKey{phys, core})
-		}
-		// Sort by phys then core
-		for i := 0; i < len(keys)-1; i++ {
-			for j := i + 1; j < len(keys); j++ {
-				if keys[i].phys > keys[j].phys || (keys[i].phys == keys[j].phys && keys[i].core > keys[j].core) {
-					keys[i], keys[j] = keys[j], keys[i]
+	if topo.Threads > 0 {
+		for _, s := range topo.Sockets {
+			for _, c := range s.Cores {
+				if spd := speedByCPU[c.Threads[0]]; spd != "" {
+					speeds = append(speeds, spd)
 				}
 			}
 		}
-		for _, k := range keys {
-			speeds = append(speeds, coreSpeedMap[fmt.Sprintf("%d-%d", k.phys, k.core)])
+		info.MHz = strings.Join(speeds, ",")
+		info.Cores = fmt.Sprintf("%d", topo.CoreCount())
+		info.Threads = fmt.Sprintf("%d", topo.Threads)
+		return info
+	}
+
+	// No sysfs topology: show all thread speeds
+	for _, p := range procs {
+		if spd := p["cpu MHz"]; spd != "" {
+			speeds = append(speeds, spd)
 		}
-		coreCount = len(coreSpeedMap)
-	} else if len(coreIDSet) > 0 {
-		// Fallback: use unique core ids
-		seen := make(map[string]bool)
-		for _, block := range blocks {
-			coreID := ""
-			speed := ""
-			lines := strings.Split(block, "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "core id") {
-					coreID = strings.TrimSpace(strings.Split(line, ":")[1])
-				}
-				if strings.HasPrefix(line, "cpu MHz") {
-					speed = strings.TrimSpace(strings.Split(line, ":")[1])
-				}
-			}
-			if coreID != "" && speed != "" && !seen[coreID] {
-				speeds = append(speeds, speed)
-				seen[coreID] = true
+	}
+	info.MHz = strings.Join(speeds, ",")
+	info.Cores = fmt.Sprintf("%d", len(procs))
+	info.Threads = info.Cores
+	return info
+}
+
+// GetCPUVulnerabilities lists /sys/devices/system/cpu/vulnerabilities with the kernel's verdict
+func GetCPUVulnerabilities() []CPUVulnerability {
+	base := "/sys/devices/system/cpu/vulnerabilities/"
+	var vulns []CPUVulnerability
+	for _, name := range listDir(base) {
+		vulns = append(vulns, CPUVulnerability{Name: name, Status: readSysString(base + name)})
+	}
+	return vulns
+}
+
+// Severity is 0 when not affected, 1 when mitigated and 2 when anything is
+// still reported vulnerable, including partial mitigations such as "BHI: Vulnerable"
+func (v CPUVulnerability) Severity() int {
+	switch {
+	case strings.Contains(v.Status, "Vulnerable"):
+		return 2
+	case strings.HasPrefix(v.Status, "Not affected"):
+		return 0
+	}
+	return 1
+}
+
+// parseCPUInfo splits /proc/cpuinfo into one key/value map per processor
+func parseCPUInfo() []map[string]string {
+	data, err := os.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return nil
+	}
+	var procs []map[string]string
+	for _, block := range strings.Split(string(data), "\n\n") {
+		fields := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			key, value, ok := strings.Cut(line, ":")
+			if !ok {
+				continue
 			}
+			fields[strings.TrimSpace(key)] = strings.TrimSpace(value)
 		}
-		coreCount = len(coreIDSet)
-	} else {
-		// Fallback: show all thread speeds
-		for _, block := range blocks {
-			speed := ""
-			lines := strings.Split(block, "\n")
-			for _, line := range lines {
-				if strings.HasPrefix(line, "cpu MHz") {
-					speed = strings.TrimSpace(strings.Split(line, ":")[1])
-				}
-			}
-			if speed != "" {
-				speeds = append(speeds, speed)
-			}
+		if _, ok := fields["processor"]; ok {
+			procs = append(procs, fields)
 		}
-		coreCount = threadCount
 	}
-	return model, strings.Join(speeds, ","), fmt.Sprintf("%d", coreCount), fmt.Sprintf("%d", threadCount)
+	return procs
 }
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// cpuRows draws one line per logical CPU: its current frequency against its
+// maximum and how busy it was over the last sample, whichever are available
+func cpuRows(freqs []CPUFreq, usage map[int]CPUUsage) []fyne.CanvasObject {
+	freqByCPU := map[int]CPUFreq{}
+	cpuSet := map[int]bool{}
+	for _, f := range freqs {
+		freqByCPU[f.CPU] = f
+		cpuSet[f.CPU] = true
+	}
+	for n := range usage {
+		cpuSet[n] = true
+	}
+	cpus := make([]int, 0, len(cpuSet))
+	for n := range cpuSet {
+		cpus = append(cpus, n)
+	}
+	sort.Ints(cpus)
+
+	var rows []fyne.CanvasObject
+	for _, n := range cpus {
+		row := container.NewHBox(
+			widget.NewLabelWithStyle(fmt.Sprintf("CPU %d:", n), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		)
+		if f, ok := freqByCPU[n]; ok {
+			fraction := 0.0
+			if f.MaxKHz > 0 {
+				fraction = float64(f.CurKHz) / float64(f.MaxKHz)
+			}
+			row.Add(container.NewCenter(makeBar(fraction, color.RGBA{40, 120, 220, 255})))
+			row.Add(widget.NewLabel(fmt.Sprintf("%4d / %d MHz", f.CurKHz/1000, f.MaxKHz/1000)))
+		}
+		if u, ok := usage[n]; ok {
+			row.Add(container.NewCenter(makeBar(u.Busy()/100, fullnessColor(u.Busy()/100))))
+			row.Add(widget.NewLabel(fmt.Sprintf("%3.0f%%", u.Busy())))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// cpuPolicyRows summarizes the cpufreq governor, EPP and boost settings
+func cpuPolicyRows(freqs []CPUFreq) []fyne.CanvasObject {
+	governors := map[string]bool{}
+	epps := map[string]bool{}
+	for _, f := range freqs {
+		if f.Governor != "" {
+			governors[f.Governor] = true
+		}
+		if f.EPP != "" {
+			epps[f.EPP] = true
+		}
+	}
+
+	var policy []string
+	if len(governors) > 0 {
+		policy = append(policy, "Governor: "+joinKeys(governors))
+	}
+	if len(epps) > 0 {
+		policy = append(policy, "EPP: "+joinKeys(epps))
+	}
+	if boost, ok := GetBoostState(); ok {
+		state := "off"
+		if boost {
+			state = "on"
+		}
+		policy = append(policy, "Boost: "+state)
+	}
+	if len(policy) == 0 {
+		return nil
+	}
+	return []fyne.CanvasObject{widget.NewLabel(strings.Join(policy, "  "))}
+}
+
+func cpuTotalRow(total CPUUsage) fyne.CanvasObject {
+	return container.NewHBox(
+		widget.NewLabelWithStyle("Total:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		container.NewCenter(makeBar(total.Busy()/100, fullnessColor(total.Busy()/100))),
+		widget.NewLabel(fmt.Sprintf("user %.0f%%  sys %.0f%%  iowait %.0f%%  steal %.0f%%  idle %.0f%%",
+			total.User, total.System, total.IOWait, total.Steal, total.Idle)),
+	)
+}
+
+// topologyRows lays out sockets → cores → threads, then caches and NUMA nodes
+func topologyRows(topo CPUTopology) []fyne.CanvasObject {
+	var lines []string
+	for _, s := range topo.Sockets {
+		kinds := map[string]int{}
+		threads := 0
+		for _, c := range s.Cores {
+			kinds[c.Kind]++
+			threads += len(c.Threads)
+		}
+		summary := fmt.Sprintf("Socket %d: %d cores", s.ID, len(s.Cores))
+		if kinds["P"] > 0 && kinds["E"] > 0 {
+			summary += fmt.Sprintf(" (%dP + %dE)", kinds["P"], kinds["E"])
+		}
+		lines = append(lines, fmt.Sprintf("%s, %d threads", summary, threads))
+		for _, c := range s.Cores {
+			name := "Core"
+			if c.Kind != "" {
+				name = c.Kind + "-core"
+			}
+			lines = append(lines, fmt.Sprintf("  %s %d: CPU %s", name, c.ID, joinInts(c.Threads)))
+		}
+	}
+
+	// Summarize caches per type and size: hybrid parts have different L2 sizes
+	// on P and E clusters, so those become separate entries tagged with the core kind
+	type cacheGroup struct {
+		name   string
+		sizeKB int
+		kind   string
+		count  int
+	}
+	var groups []*cacheGroup
+	for _, c := range topo.Caches {
+		kind := ""
+		if len(c.SharedCPUs) > 0 {
+			if _, core, ok := topo.CoreOf(c.SharedCPUs[0]); ok {
+				kind = core.Kind
+			}
+		}
+		var g *cacheGroup
+		for _, existing := range groups {
+			if existing.name == c.Name() && existing.sizeKB == c.SizeKB {
+				g = existing
+				break
+			}
+		}
+		if g == nil {
+			g = &cacheGroup{name: c.Name(), sizeKB: c.SizeKB, kind: kind}
+			groups = append(groups, g)
+		} else if g.kind != kind {
+			g.kind = ""
+		}
+		g.count++
+	}
+	sizesPerName := map[string]int{}
+	for _, g := range groups {
+		sizesPerName[g.name]++
+	}
+	var cacheParts []string
+	for _, g := range groups {
+		part := fmt.Sprintf("%s %s ×%d", g.name, formatBytes(uint64(g.sizeKB)*1024), g.count)
+		if sizesPerName[g.name] > 1 && g.kind != "" {
+			part += fmt.Sprintf(" (%s-cores)", g.kind)
+		}
+		cacheParts = append(cacheParts, part)
+	}
+	if len(cacheParts) > 0 {
+		lines = append(lines, "Cache: "+strings.Join(cacheParts, ", "))
+	}
+
+	if len(topo.NUMANodes) > 1 {
+		nodes := make([]int, 0, len(topo.NUMANodes))
+		for n := range topo.NUMANodes {
+			nodes = append(nodes, n)
+		}
+		sort.Ints(nodes)
+		for _, n := range nodes {
+			lines = append(lines, fmt.Sprintf("NUMA node %d: CPU %s", n, joinInts(topo.NUMANodes[n])))
+		}
+	}
+
+	var rows []fyne.CanvasObject
+	for _, line := range lines {
+		txt := canvas.NewText(line, color.White)
+		txt.TextSize = 13
+		txt.TextStyle = fyne.TextStyle{Monospace: true}
+		rows = append(rows, txt)
+	}
+	return rows
+}
+
+func MakeCPUSecurityCard(info CPUInfo, vulns []CPUVulnerability) fyne.CanvasObject {
+	ident := []string{
+		fmt.Sprintf("Vendor: %s  Family: %s  Model: %s  Stepping: %s", info.Vendor, info.Family, info.ModelID, info.Stepping),
+		fmt.Sprintf("Microcode: %s", info.Microcode),
+	}
+	if len(info.Flags) > 0 {
+		ident = append(ident, "Flags: "+strings.Join(info.Flags, " "))
+	}
+	rows := []fyne.CanvasObject{widget.NewLabel(strings.Join(ident, "\n"))}
+
+	severityColors := []color.Color{
+		color.RGBA{0, 180, 0, 255},
+		color.RGBA{230, 160, 0, 255},
+		color.RGBA{220, 0, 0, 255},
+	}
+	for _, v := range vulns {
+		txt := canvas.NewText(fmt.Sprintf("%s: %s", v.Name, v.Status), severityColors[v.Severity()])
+		txt.TextSize = 12
+		txt.TextStyle = fyne.TextStyle{Monospace: true}
+		rows = append(rows, txt)
+	}
+	return widget.NewCard("CPU Security", "Vulnerabilities and microcode", container.NewVBox(rows...))
+}
+
+// throttleRows lists throttle counters, red for anything that throttled this session
+func throttleRows(counters []ThrottleCounter) []fyne.CanvasObject {
+	if len(counters) == 0 {
+		return nil
+	}
+	rows := []fyne.CanvasObject{
+		widget.NewLabelWithStyle("Thermal Throttling", fyne.TextAlignLeading, fyne.TextStyle{Bold: true, Italic: true}),
+	}
+	for _, t := range counters {
+		// Per-core counters that never fired would just be noise
+		if t.Count == 0 && strings.HasPrefix(t.Name, "Core") {
+			continue
+		}
+		rowColor := color.Color(color.RGBA{0, 180, 0, 255})
+		if t.SessionCount > 0 {
+			rowColor = color.RGBA{220, 0, 0, 255}
+		}
+		txt := canvas.NewText(fmt.Sprintf("%s: %d since boot, %d this session", t.Name, t.Count, t.SessionCount), rowColor)
+		txt.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, txt)
+	}
+	return rows
+}
+
+func gpuThrottleRows(reasons map[string][]string) []fyne.CanvasObject {
+	var rows []fyne.CanvasObject
+	for _, card := range sortedCards(reasons) {
+		r := reasons[card]
+		txt := canvas.NewText(fmt.Sprintf("%s throttled: %s", card, strings.Join(r, ", ")), color.RGBA{220, 0, 0, 255})
+		txt.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, txt)
+	}
+	return rows
+}
+
+// MakePowerStatesCard draws session histograms of C-state and frequency residency
+func MakePowerStatesCard(cstates, freqs []StateShare) fyne.CanvasObject {
+	histogram := func(title string, shares []StateShare, fill color.Color) []fyne.CanvasObject {
+		if len(shares) == 0 {
+			return nil
+		}
+		rows := []fyne.CanvasObject{
+			widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true, Italic: true}),
+		}
+		for _, s := range shares {
+			name := canvas.NewText(fmt.Sprintf("%-12s", s.Name), color.White)
+			name.TextStyle = fyne.TextStyle{Monospace: true}
+			rows = append(rows, container.NewHBox(
+				name,
+				container.NewCenter(makeBar(s.Fraction, fill)),
+				widget.NewLabel(fmt.Sprintf("%.1f%%", s.Fraction*100)),
+			))
+		}
+		return rows
+	}
+	rows := histogram("Idle states", cstates, color.RGBA{40, 160, 120, 255})
+	rows = append(rows, histogram("Frequencies", freqs, color.RGBA{40, 120, 220, 255})...)
+	if len(rows) == 0 {
+		rows = append(rows, widget.NewLabel("No cpuidle or cpufreq statistics available."))
+	}
+	return widget.NewCard("CPU Power States", "Residency this session", container.NewVBox(rows...))
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%d", v)
+	}
+	return strings.Join(parts, ",")
+}
+
+// sortedCards keeps multi-GPU rows and alerts in a stable order
+func sortedCards(reasons map[string][]string) []string {
+	cards := make([]string, 0, len(reasons))
+	for card := range reasons {
+		cards = append(cards, card)
+	}
+	sort.Strings(cards)
+	return cards
+}
+
+func joinKeys(set map[string]bool) string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
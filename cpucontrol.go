@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// CPUControlPanel holds the governor/EPP/boost widgets. It is built once and
+// reused across refreshes so open dropdowns and toggles keep their state.
+type CPUControlPanel struct {
+	box          *fyne.Container
+	notices      *NoticeBar
+	RevertOnExit bool
+}
+
+func NewCPUControlPanel(notices *NoticeBar, revertOnExit bool) *CPUControlPanel {
+	p := &CPUControlPanel{box: container.NewVBox(), notices: notices, RevertOnExit: revertOnExit}
+	p.rebuild()
+	return p
+}
+
+func (p *CPUControlPanel) Object() fyne.CanvasObject {
+	return p.box
+}
+
+// rebuild re-reads sysfs and recreates the controls, e.g. after a governor
+// change altered which preferences are available
+func (p *CPUControlPanel) rebuild() {
+	policies := GetCPUPolicies()
+	var rows []fyne.CanvasObject
+	if len(policies) == 0 {
+		p.box.Objects = nil
+		p.box.Refresh()
+		return
+	}
+	rows = append(rows, widget.NewLabelWithStyle("Control", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+
+	// "All" applies to every policy; only offer choices every policy supports
+	governors := policies[0].Governors
+	epps := policies[0].EPPs
+	for _, pol := range policies[1:] {
+		governors = intersectStrings(governors, pol.Governors)
+		epps = intersectStrings(epps, pol.EPPs)
+	}
+	if len(policies) > 1 {
+		rows = append(rows, p.policyRow("All", "", "", governors, epps, func(gov string) error {
+			for _, pol := range policies {
+				if err := pol.SetGovernor(gov); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, func(epp string) error {
+			for _, pol := range policies {
+				if err := pol.SetEPP(epp); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+	}
+	for _, pol := range policies {
+		pol := pol
+		name := fmt.Sprintf("%s (CPU %s)", pol.Name, joinInts(pol.CPUs))
+		rows = append(rows, p.policyRow(name, pol.Governor, pol.EPP, pol.Governors, pol.EPPs, pol.SetGovernor, pol.SetEPP))
+	}
+
+	var toggles []fyne.CanvasObject
+	if enabled, ok := GetBoostState(); ok {
+		boost := widget.NewCheck("Boost", nil)
+		boost.SetChecked(enabled)
+		boost.OnChanged = func(on bool) {
+			p.apply(func() error { return SetBoost(on) })
+		}
+		toggles = append(toggles, boost)
+	}
+	revert := widget.NewCheck("Revert on exit", func(on bool) { p.RevertOnExit = on })
+	revert.SetChecked(p.RevertOnExit)
+	toggles = append(toggles, revert)
+	rows = append(rows, container.NewHBox(toggles...))
+
+	p.box.Objects = rows
+	p.box.Refresh()
+}
+
+func (p *CPUControlPanel) policyRow(name, governor, epp string, governors, epps []string, setGovernor, setEPP func(string) error) fyne.CanvasObject {
+	row := container.NewHBox(widget.NewLabel(name))
+	if len(governors) > 0 {
+		sel := widget.NewSelect(governors, nil)
+		sel.PlaceHolder = "governor"
+		sel.Selected = governor
+		sel.OnChanged = func(v string) {
+			p.apply(func() error { return setGovernor(v) })
+		}
+		row.Add(sel)
+	}
+	if len(epps) > 0 {
+		sel := widget.NewSelect(epps, nil)
+		sel.PlaceHolder = "EPP"
+		sel.Selected = epp
+		sel.OnChanged = func(v string) {
+			p.apply(func() error { return setEPP(v) })
+		}
+		row.Add(sel)
+	}
+	return row
+}
+
+// apply runs a setter, reports failures and resyncs the widgets with sysfs
+func (p *CPUControlPanel) apply(set func() error) {
+	if err := set(); err != nil {
+		p.notices.Alert(err.Error(), 10*time.Second)
+	}
+	// Rebuilding from inside a widget callback would swap it out mid-event
+	go fyne.Do(p.rebuild)
+}
+
+func intersectStrings(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if containsString(b, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CPUFreq struct {
+	CPU      int
+	CurKHz   uint64
+	MinKHz   uint64
+	MaxKHz   uint64
+	Governor string
+	EPP      string // energy_performance_preference, empty when the driver has none
+}
+
+// GetCPUFreqs reads cpufreq for every logical CPU that has a policy, sorted by CPU number
+func GetCPUFreqs() []CPUFreq {
+	dirs, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpufreq")
+	var freqs []CPUFreq
+	for _, dir := range dirs {
+		n, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(dir)), "cpu"))
+		if err != nil {
+			continue
+		}
+		base := dir + "/"
+		f := CPUFreq{
+			CPU:      n,
+			Governor: readSysString(base + "scaling_governor"),
+			EPP:      readSysString(base + "energy_performance_preference"),
+		}
+		f.CurKHz, _ = strconv.ParseUint(readSysString(base+"scaling_cur_freq"), 10, 64)
+		f.MinKHz, _ = strconv.ParseUint(readSysString(base+"cpuinfo_min_freq"), 10, 64)
+		f.MaxKHz, _ = strconv.ParseUint(readSysString(base+"cpuinfo_max_freq"), 10, 64)
+		freqs = append(freqs, f)
+	}
+	sort.Slice(freqs, func(i, j int) bool { return freqs[i].CPU < freqs[j].CPU })
+	return freqs
+}
+
+// GetBoostState reports whether turbo/boost is enabled. acpi-cpufreq and
+// amd-pstate expose cpufreq/boost, intel_pstate the inverted no_turbo.
+func GetBoostState() (enabled bool, ok bool) {
+	if v := readSysString("/sys/devices/system/cpu/cpufreq/boost"); v != "" {
+		return v == "1", true
+	}
+	if v := readSysString("/sys/devices/system/cpu/intel_pstate/no_turbo"); v != "" {
+		return v == "0", true
+	}
+	return false, false
+}
+
+type CPUPolicy struct {
+	Name       string // policy0, policy1, ...
+	CPUs       []int
+	Governor   string
+	Governors  []string
+	EPP        string
+	EPPs       []string
+	policyPath string
+}
+
+const cpufreqBase = "/sys/devices/system/cpu/cpufreq/"
+
+func GetCPUPolicies() []CPUPolicy {
+	dirs, _ := filepath.Glob(cpufreqBase + "policy[0-9]*")
+	var policies []CPUPolicy
+	for _, dir := range dirs {
+		base := dir + "/"
+		policies = append(policies, CPUPolicy{
+			Name:       filepath.Base(dir),
+			CPUs:       parseCPUList(readSysString(base + "related_cpus")),
+			Governor:   readSysString(base + "scaling_governor"),
+			Governors:  strings.Fields(readSysString(base + "scaling_available_governors")),
+			EPP:        readSysString(base + "energy_performance_preference"),
+			EPPs:       strings.Fields(readSysString(base + "energy_performance_available_preferences")),
+			policyPath: base,
+		})
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(policies[i].Name, "policy"))
+		b, _ := strconv.Atoi(strings.TrimPrefix(policies[j].Name, "policy"))
+		return a < b
+	})
+	return policies
+}
+
+func (p CPUPolicy) SetGovernor(governor string) error {
+	if !containsString(p.Governors, governor) {
+		return fmt.Errorf("%s: governor %q not in scaling_available_governors", p.Name, governor)
+	}
+	return writeCPUSetting(p.policyPath+"scaling_governor", governor)
+}
+
+func (p CPUPolicy) SetEPP(epp string) error {
+	if !containsString(p.EPPs, epp) {
+		return fmt.Errorf("%s: %q not in energy_performance_available_preferences", p.Name, epp)
+	}
+	return writeCPUSetting(p.policyPath+"energy_performance_preference", epp)
+}
+
+// SetBoost toggles turbo through whichever interface GetBoostState found
+func SetBoost(enabled bool) error {
+	if readSysString(cpufreqBase+"boost") != "" {
+		value := "0"
+		if enabled {
+			value = "1"
+		}
+		return writeCPUSetting(cpufreqBase+"boost", value)
+	}
+	if readSysString("/sys/devices/system/cpu/intel_pstate/no_turbo") != "" {
+		value := "1"
+		if enabled {
+			value = "0"
+		}
+		return writeCPUSetting("/sys/devices/system/cpu/intel_pstate/no_turbo", value)
+	}
+	return fmt.Errorf("no boost control found")
+}
+
+// Value each setting had before jayinsights first changed it, in change order.
+// Only touched on the Fyne goroutine.
+var cpuSettingOriginals = map[string]string{}
+var cpuSettingOrder []string
+
+func writeCPUSetting(path, value string) error {
+	original := readSysString(path)
+	if err := os.WriteFile(path, []byte(value), 0644); err != nil {
+		if os.IsPermission(err) {
+			return fmt.Errorf("changing %s requires root", filepath.Base(path))
+		}
+		return err
+	}
+	// Only a write that took effect has anything to revert
+	if _, saved := cpuSettingOriginals[path]; !saved {
+		cpuSettingOriginals[path] = original
+		cpuSettingOrder = append(cpuSettingOrder, path)
+	}
+	return nil
+}
+
+// RevertCPUSettings restores everything writeCPUSetting changed, newest first
+// so that e.g. EPP is restored before the governor it depended on
+func RevertCPUSettings() {
+	for i := len(cpuSettingOrder) - 1; i >= 0; i-- {
+		path := cpuSettingOrder[i]
+		if err := os.WriteFile(path, []byte(cpuSettingOriginals[path]), 0644); err != nil {
+			fmt.Printf("Error restoring %s: %v\n", path, err)
+		}
+	}
+	cpuSettingOriginals = map[string]string{}
+	cpuSettingOrder = nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+type cpuTimes struct {
+	user, nice, system, idle, iowait, irq, softirq, steal uint64
+}
+
+func (t cpuTimes) total() uint64 {
+	// guest time is already included in user, so it isn't added again
+	return t.user + t.nice + t.system + t.idle + t.iowait + t.irq + t.softirq + t.steal
+}
+
+// CPUUsage holds percentages of the interval between two samples
+type CPUUsage struct {
+	User   float64 // user + nice
+	System float64 // system + irq + softirq
+	IOWait float64
+	Steal  float64
+	Idle   float64
+}
+
+func (u CPUUsage) Busy() float64 {
+	return u.User + u.System + u.Steal
+}
+
+// CPUUsageSampler diffs /proc/stat between calls
+type CPUUsageSampler struct {
+	prevTotal cpuTimes
+	prevCPUs  map[int]cpuTimes
+	primed    bool
+}
+
+func NewCPUUsageSampler() *CPUUsageSampler {
+	return &CPUUsageSampler{prevCPUs: map[int]cpuTimes{}}
+}
+
+// Sample returns overall and per logical CPU usage since the previous call;
+// ok is false on the first call
+func (s *CPUUsageSampler) Sample() (total CPUUsage, perCPU map[int]CPUUsage, ok bool) {
+	data, err := os.ReadFile("/proc/stat")
+	if err != nil {
+		return CPUUsage{}, nil, false
+	}
+	curTotal, curCPUs := parseProcStat(string(data))
+	perCPU = map[int]CPUUsage{}
+	for n, cur := range curCPUs {
+		if prev, seen := s.prevCPUs[n]; seen {
+			perCPU[n] = cpuUsageBetween(prev, cur)
+		}
+	}
+	total = cpuUsageBetween(s.prevTotal, curTotal)
+	ok = s.primed
+	s.prevTotal, s.prevCPUs, s.primed = curTotal, curCPUs, true
+	return total, perCPU, ok
+}
+
+func cpuUsageBetween(prev, cur cpuTimes) CPUUsage {
+	delta := func(a, b uint64) float64 {
+		// Offlined CPUs can come back with reset counters
+		if b < a {
+			return 0
+		}
+		return float64(b - a)
+	}
+	elapsed := delta(prev.total(), cur.total())
+	if elapsed == 0 {
+		return CPUUsage{Idle: 100}
+	}
+	pct := func(v float64) float64 { return v / elapsed * 100 }
+	return CPUUsage{
+		User:   pct(delta(prev.user, cur.user) + delta(prev.nice, cur.nice)),
+		System: pct(delta(prev.system, cur.system) + delta(prev.irq, cur.irq) + delta(prev.softirq, cur.softirq)),
+		IOWait: pct(delta(prev.iowait, cur.iowait)),
+		Steal:  pct(delta(prev.steal, cur.steal)),
+		Idle:   pct(delta(prev.idle, cur.idle)),
+	}
+}
+
+// parseProcStat reads the aggregate "cpu" line and every "cpuN" line
+func parseProcStat(data string) (cpuTimes, map[int]cpuTimes) {
+	var total cpuTimes
+	cpus := map[int]cpuTimes{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 9 || !strings.HasPrefix(fields[0], "cpu") {
+			continue
+		}
+		var v [8]uint64
+		for i := range v {
+			v[i], _ = strconv.ParseUint(fields[i+1], 10, 64)
+		}
+		t := cpuTimes{user: v[0], nice: v[1], system: v[2], idle: v[3], iowait: v[4], irq: v[5], softirq: v[6], steal: v[7]}
+		if fields[0] == "cpu" {
+			total = t
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(fields[0], "cpu")); err == nil {
+			cpus[n] = t
+		}
+	}
+	return total, cpus
+}
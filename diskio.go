@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// /sys/block/<dev>/stat always counts 512 byte sectors, whatever the drive uses
+const statSectorSize = 512
+
+type diskStat struct {
+	readIOs      uint64
+	readSectors  uint64
+	readTicks    uint64
+	writeIOs     uint64
+	writeSectors uint64
+	writeTicks   uint64
+	ioTicks      uint64
+}
+
+type DiskIO struct {
+	ReadMBps     float64
+	WriteMBps    float64
+	ReadIOPS     float64
+	WriteIOPS    float64
+	UtilPercent  float64
+	AvgLatencyMs float64
+}
+
+// DiskIOSampler keeps the previous /sys/block/<dev>/stat snapshot so each
+// call can report rates over the interval since the last one
+type DiskIOSampler struct {
+	prev     map[string]diskStat
+	prevTime time.Time
+}
+
+func NewDiskIOSampler() *DiskIOSampler {
+	return &DiskIOSampler{prev: map[string]diskStat{}}
+}
+
+// Sample returns rates for every device that was also present in the previous sample
+func (s *DiskIOSampler) Sample(devs []string) map[string]DiskIO {
+	now := time.Now()
+	elapsed := now.Sub(s.prevTime).Seconds()
+	current := map[string]diskStat{}
+	result := map[string]DiskIO{}
+	for _, dev := range devs {
+		st, err := readDiskStat(dev)
+		if err != nil {
+			continue
+		}
+		current[dev] = st
+		prev, ok := s.prev[dev]
+		if !ok || elapsed <= 0 {
+			continue
+		}
+		result[dev] = diskIORates(prev, st, elapsed)
+	}
+	s.prev = current
+	s.prevTime = now
+	return result
+}
+
+func diskIORates(prev, cur diskStat, elapsed float64) DiskIO {
+	delta := func(a, b uint64) float64 {
+		// Counters reset when a device is re-added
+		if b < a {
+			return 0
+		}
+		return float64(b - a)
+	}
+	ios := delta(prev.readIOs, cur.readIOs) + delta(prev.writeIOs, cur.writeIOs)
+	io := DiskIO{
+		ReadMBps:    delta(prev.readSectors, cur.readSectors) * statSectorSize / 1e6 / elapsed,
+		WriteMBps:   delta(prev.writeSectors, cur.writeSectors) * statSectorSize / 1e6 / elapsed,
+		ReadIOPS:    delta(prev.readIOs, cur.readIOs) / elapsed,
+		WriteIOPS:   delta(prev.writeIOs, cur.writeIOs) / elapsed,
+		UtilPercent: delta(prev.ioTicks, cur.ioTicks) / (elapsed * 1000) * 100,
+	}
+	if io.UtilPercent > 100 {
+		io.UtilPercent = 100
+	}
+	if ios > 0 {
+		io.AvgLatencyMs = (delta(prev.readTicks, cur.readTicks) + delta(prev.writeTicks, cur.writeTicks)) / ios
+	}
+	return io
+}
+
+func readDiskStat(dev string) (diskStat, error) {
+	data, err := os.ReadFile(fmt.Sprintf("/sys/block/%s/stat", dev))
+	if err != nil {
+		return diskStat{}, err
+	}
+	return parseDiskStat(string(data))
+}
+
+// parseDiskStat reads the field layout documented in Documentation/block/stat.rst
+func parseDiskStat(line string) (diskStat, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 11 {
+		return diskStat{}, fmt.Errorf("unexpected stat format: %q", line)
+	}
+	vals := make([]uint64, 11)
+	for i := range vals {
+		v, err := strconv.ParseUint(fields[i], 10, 64)
+		if err != nil {
+			return diskStat{}, err
+		}
+		vals[i] = v
+	}
+	return diskStat{
+		readIOs:      vals[0],
+		readSectors:  vals[2],
+		readTicks:    vals[3],
+		writeIOs:     vals[4],
+		writeSectors: vals[6],
+		writeTicks:   vals[7],
+		ioTicks:      vals[9],
+	}, nil
+}
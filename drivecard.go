@@ -0,0 +1,310 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Used when a drive doesn't report its own warning limit
+const defaultDriveTempThreshold = 70.0
+
+// health is nil when SMART data couldn't be read (usually not running as root),
+// io is nil until a second stat sample is available
+func MakeDriveCard(d Drive, treeRows []PartitionRow, temps []DriveTemp, health *DriveHealth, io *DiskIO) fyne.CanvasObject {
+	rows := []fyne.CanvasObject{}
+	var details []string
+	if d.Serial != "" {
+		details = append(details, fmt.Sprintf("Serial: %s", d.Serial))
+	}
+	if d.Firmware != "" {
+		details = append(details, fmt.Sprintf("Firmware: %s", d.Firmware))
+	}
+	kind := d.Kind()
+	if d.Removable {
+		kind += ", removable"
+	}
+	details = append(details, fmt.Sprintf("Type: %s  Interface: %s  Size: %s", kind, d.Transport, formatBytes(d.Bytes)))
+	if d.LogicalSectorSize > 0 {
+		details = append(details, fmt.Sprintf("Sectors: %d B logical / %d B physical", d.LogicalSectorSize, d.PhysicalSectorSize))
+	}
+	if d.Scheduler != "" {
+		details = append(details, fmt.Sprintf("Scheduler: %s", d.Scheduler))
+	}
+	if len(d.Namespaces) > 0 {
+		details = append(details, fmt.Sprintf("Namespaces: %s", strings.Join(d.Namespaces, ", ")))
+	}
+	if len(details) > 0 {
+		rows = append(rows, widget.NewLabel(strings.Join(details, "\n")))
+	}
+	if d.LinkSpeed != "" {
+		rows = append(rows, linkRow(d))
+	}
+
+	for _, t := range temps {
+		threshold := t.Warning
+		if threshold <= 0 {
+			threshold = defaultDriveTempThreshold
+		}
+		var limits []string
+		if t.Warning > 0 {
+			limits = append(limits, fmt.Sprintf("warn %.0f°C", t.Warning))
+		}
+		if t.Critical > 0 {
+			limits = append(limits, fmt.Sprintf("crit %.0f°C", t.Critical))
+		}
+		row := container.NewHBox(
+			widget.NewLabelWithStyle(t.Label+":", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			tempText(t.Current, threshold),
+		)
+		if len(limits) > 0 {
+			row.Add(widget.NewLabel("(" + strings.Join(limits, ", ") + ")"))
+		}
+		rows = append(rows, row)
+	}
+	if io != nil {
+		rows = append(rows, ioRows(io)...)
+	}
+	if health != nil {
+		rows = append(rows, healthRows(health)...)
+	}
+	rows = append(rows, partitionRows(treeRows)...)
+
+	return widget.NewCard(
+		fmt.Sprintf("Drive: %s", d.Name),
+		fmt.Sprintf("Model: %s", d.Model),
+		container.NewVBox(rows...),
+	)
+}
+
+func partitionRows(tree []PartitionRow) []fyne.CanvasObject {
+	var rows []fyne.CanvasObject
+	for _, r := range tree {
+		line := canvas.NewText(r.Text, color.White)
+		line.TextStyle = fyne.TextStyle{Monospace: true}
+		if r.Usage == nil {
+			rows = append(rows, line)
+			continue
+		}
+		u := r.Usage
+		used := u.UsedFraction()
+		summary := fmt.Sprintf("%.0f%%  %s of %s, %s free", used*100,
+			formatBytes(u.UsedBytes), formatBytes(u.TotalBytes), formatBytes(u.FreeBytes))
+		if u.Inodes > 0 {
+			summary += fmt.Sprintf("  inodes %.0f%%", u.InodeFraction()*100)
+		}
+		fsInfo := canvas.NewText(fmt.Sprintf("    %s (%s)", u.FSType, u.Options), color.Gray{Y: 170})
+		fsInfo.TextSize = 11
+		fsInfo.TextStyle = fyne.TextStyle{Monospace: true}
+		// A filesystem nearly out of inodes is just as full as one out of blocks
+		fill := used
+		if u.InodeFraction() > fill {
+			fill = u.InodeFraction()
+		}
+		rows = append(rows,
+			line,
+			container.NewHBox(
+				widget.NewLabel("   "),
+				container.NewCenter(makeBar(used, fullnessColor(fill))),
+				widget.NewLabel(summary),
+			),
+			fsInfo,
+		)
+	}
+	return rows
+}
+
+func ioRows(io *DiskIO) []fyne.CanvasObject {
+	return []fyne.CanvasObject{
+		container.NewHBox(
+			widget.NewLabelWithStyle("Busy:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.NewCenter(makeBar(io.UtilPercent/100, fullnessColor(io.UtilPercent/100))),
+			widget.NewLabel(fmt.Sprintf("%.0f%%  Latency: %.2f ms", io.UtilPercent, io.AvgLatencyMs)),
+		),
+		widget.NewLabel(fmt.Sprintf("Read: %.1f MB/s (%.0f IOPS)  Write: %.1f MB/s (%.0f IOPS)",
+			io.ReadMBps, io.ReadIOPS, io.WriteMBps, io.WriteIOPS)),
+	}
+}
+
+func linkRow(d Drive) fyne.CanvasObject {
+	link := fmt.Sprintf("%s x%d", d.LinkSpeed, d.LinkWidth)
+	linkColor := color.Color(color.White)
+	if d.LinkDegraded() {
+		// A drive in a slot that can't feed it full lanes/speed is easy to miss
+		link += fmt.Sprintf(" (degraded, max %s x%d)", d.MaxLinkSpeed, d.MaxLinkWidth)
+		linkColor = color.RGBA{220, 0, 0, 255}
+	}
+	txt := canvas.NewText(link, linkColor)
+	txt.TextStyle = fyne.TextStyle{Bold: true}
+	return container.NewHBox(
+		widget.NewLabelWithStyle("PCIe Link:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		txt,
+	)
+}
+
+func healthRows(h *DriveHealth) []fyne.CanvasObject {
+	status := canvas.NewText("PASSED", color.RGBA{0, 180, 0, 255})
+	if !h.Passed {
+		status = canvas.NewText("FAILED", color.RGBA{220, 0, 0, 255})
+	}
+	status.TextStyle = fyne.TextStyle{Bold: true}
+
+	used := "N/A"
+	if h.PercentUsed >= 0 {
+		used = fmt.Sprintf("%d%%", h.PercentUsed)
+	}
+	stats := []string{
+		fmt.Sprintf("Wear used: %s  Power-on: %d h  Power cycles: %d", used, h.PowerOnHours, h.PowerCycles),
+		fmt.Sprintf("Unsafe shutdowns: %d  Media errors: %d", h.UnsafeShutdowns, h.MediaErrors),
+	}
+	if h.Protocol == "ATA" {
+		stats[1] += fmt.Sprintf("  Reallocated: %d", h.ReallocatedSectors)
+	}
+	if h.Temperature > 0 {
+		stats[0] += fmt.Sprintf("  Temp: %d°C", h.Temperature)
+	}
+
+	rows := []fyne.CanvasObject{
+		container.NewHBox(
+			widget.NewLabelWithStyle(fmt.Sprintf("Health (%s):", h.Protocol), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			status,
+		),
+		widget.NewLabel(strings.Join(stats, "\n")),
+	}
+	// Highlight anything the drive itself considers out of spec
+	for _, f := range h.Failing {
+		txt := canvas.NewText("⚠ "+f, color.RGBA{220, 0, 0, 255})
+		txt.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, txt)
+	}
+	return rows
+}
+
+func MakeRAIDCard(arrays []RAIDArray) fyne.CanvasObject {
+	red := color.RGBA{220, 0, 0, 255}
+	green := color.RGBA{0, 180, 0, 255}
+	var rows []fyne.CanvasObject
+	for _, a := range arrays {
+		stateColor := color.Color(green)
+		if a.Degraded > 0 {
+			stateColor = red
+		}
+		state := canvas.NewText(fmt.Sprintf("%s %s", a.State, a.Status), stateColor)
+		state.TextStyle = fyne.TextStyle{Bold: true}
+		rows = append(rows, container.NewHBox(
+			widget.NewLabelWithStyle(fmt.Sprintf("%s (%s):", a.Name, a.Level), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			state,
+		))
+		if a.Degraded > 0 {
+			warn := canvas.NewText(fmt.Sprintf("⚠ Degraded: %d of %d members missing", a.Degraded, a.RaidDisks), red)
+			warn.TextStyle = fyne.TextStyle{Bold: true}
+			rows = append(rows, warn)
+		}
+		for _, m := range a.Members {
+			memberColor := color.Color(green)
+			switch {
+			case !m.Healthy():
+				memberColor = red
+			case m.Spare():
+				memberColor = color.Gray{Y: 170}
+			}
+			txt := canvas.NewText(fmt.Sprintf("  %s: %s", m.Name, m.State), memberColor)
+			txt.TextStyle = fyne.TextStyle{Monospace: true}
+			rows = append(rows, txt)
+		}
+		if a.Syncing() {
+			progress := fmt.Sprintf("%s %.1f%%", a.SyncAction, a.Progress()*100)
+			if eta := a.ETA(); eta > 0 {
+				progress += fmt.Sprintf("  ETA %s", eta.Round(time.Minute))
+			}
+			rows = append(rows, container.NewHBox(
+				widget.NewLabel("  "),
+				container.NewCenter(makeBar(a.Progress(), color.RGBA{40, 120, 220, 255})),
+				widget.NewLabel(progress),
+			))
+		}
+	}
+	return widget.NewCard("RAID Arrays", "", container.NewVBox(rows...))
+}
+
+func MakePoolsCard(btrfs []BtrfsFS, zfs *ZFSInfo) fyne.CanvasObject {
+	red := color.RGBA{220, 0, 0, 255}
+	var rows []fyne.CanvasObject
+	for _, fs := range btrfs {
+		name := fs.Label
+		if name == "" {
+			name = fs.UUID[:8]
+		}
+		title := fmt.Sprintf("btrfs %s", name)
+		if fs.MountPoint != "" {
+			title += " on " + fs.MountPoint
+		}
+		rows = append(rows,
+			widget.NewLabelWithStyle(title, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			widget.NewLabel(fmt.Sprintf("Devices: %s", strings.Join(fs.Devices, ", "))),
+			allocationRow("Data", fs.DataProfile, fs.DataUsed, fs.DataTotal),
+			allocationRow("Metadata", fs.MetadataProfile, fs.MetadataUsed, fs.MetadataTotal),
+		)
+		if errs := fs.ErrorCount(); errs > 0 {
+			var parts []string
+			for k, v := range fs.Errors {
+				if v > 0 {
+					parts = append(parts, fmt.Sprintf("%s %d", k, v))
+				}
+			}
+			// Map order would reshuffle the line on every refresh
+			sort.Strings(parts)
+			warn := canvas.NewText("⚠ Device errors: "+strings.Join(parts, ", "), red)
+			warn.TextStyle = fyne.TextStyle{Bold: true}
+			rows = append(rows, warn)
+		}
+	}
+	if zfs != nil {
+		for _, p := range zfs.Pools {
+			stateColor := color.Color(color.RGBA{0, 180, 0, 255})
+			if p.State != "ONLINE" {
+				stateColor = red
+			}
+			state := canvas.NewText(p.State, stateColor)
+			state.TextStyle = fyne.TextStyle{Bold: true}
+			rows = append(rows, container.NewHBox(
+				widget.NewLabelWithStyle(fmt.Sprintf("zpool %s:", p.Name), fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				state,
+			))
+		}
+		if zfs.ARCMax > 0 {
+			rows = append(rows, container.NewHBox(
+				widget.NewLabel("ARC:"),
+				container.NewCenter(makeBar(float64(zfs.ARCSize)/float64(zfs.ARCMax), color.RGBA{40, 120, 220, 255})),
+				widget.NewLabel(fmt.Sprintf("%s of %s, hit rate %.1f%%", formatBytes(zfs.ARCSize), formatBytes(zfs.ARCMax), zfs.ARCHitRate()*100)),
+			))
+		}
+	}
+	return widget.NewCard("Pools", "", container.NewVBox(rows...))
+}
+
+func allocationRow(kind, profile string, used, total uint64) fyne.CanvasObject {
+	fraction := 0.0
+	if total > 0 {
+		fraction = float64(used) / float64(total)
+	}
+	return container.NewHBox(
+		widget.NewLabel(fmt.Sprintf("%s (%s):", kind, profile)),
+		container.NewCenter(makeBar(fraction, fullnessColor(fraction))),
+		widget.NewLabel(fmt.Sprintf("%s of %s allocated", formatBytes(used), formatBytes(total))),
+	)
+}
+
+func driveNotice(action string, d Drive) string {
+	if d.Transport == "USB" {
+		return fmt.Sprintf("USB drive %s: %s", action, d.Model)
+	}
+	return fmt.Sprintf("Drive %s: %s (%s)", action, d.Name, d.Model)
+}
@@ -3,25 +3,242 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
+type Drive struct {
+	Name       string
+	Model      string
+	Serial     string
+	Firmware   string
+	Transport  string // SATA, NVMe, USB, virtio, ...
+	Namespaces []string
+	Bytes      uint64
+	Rotational bool
+	Removable  bool
+	Scheduler  string
+	// Sector sizes in bytes
+	LogicalSectorSize  int
+	PhysicalSectorSize int
+	// PCIe link of the controller, only filled in for nvme drives
+	LinkSpeed    string
+	LinkWidth    int
+	MaxLinkSpeed string
+	MaxLinkWidth int
+}
+
+// Namespace block devices live under the controller as nvme0n1, or nvme0c0n1 with native multipath
+var nvmeNamespaceRe = regexp.MustCompile(`^nvme\d+(c\d+)?n\d+$`)
+
 func GetDrives() []string {
 	drives := []string{}
 	files, _ := os.ReadDir("/sys/block/")
 	for _, f := range files {
-		if strings.HasPrefix(f.Name(), "sd") || strings.HasPrefix(f.Name(), "nvme") {
+		if isDisplayedDrive(f.Name()) {
 			drives = append(drives, f.Name())
 		}
 	}
 	return drives
 }
 
+// isDisplayedDrive decides from sysfs attributes rather than the name whether a
+// /sys/block entry gets its own drive card
+func isDisplayedDrive(dev string) bool {
+	base := "/sys/block/" + dev + "/"
+	// Empty card readers, unused loop/nbd slots and ejected optical drives
+	if size, err := strconv.ParseUint(readSysString(base+"size"), 10, 64); err != nil || size == 0 {
+		return false
+	}
+	// device-mapper devices are shown inside the tree of the disks they sit on,
+	// loop devices are just files on another filesystem
+	if pathExists(base+"dm") || pathExists(base+"loop") {
+		return false
+	}
+	realPath, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		return false
+	}
+	if strings.HasPrefix(realPath, "/sys/devices/virtual/") {
+		// Of the purely virtual devices only software RAID and compressed swap are worth a card
+		return pathExists(base+"md") || pathExists(base+"comp_algorithm")
+	}
+	return true
+}
+
 func GetDriveModel(dev string) string {
-	path := fmt.Sprintf("/sys/block/%s/device/model", dev)
+	base := fmt.Sprintf("/sys/block/%s/", dev)
+	if model := readSysString(base + "device/model"); model != "" {
+		return model
+	}
+	// MMC/SD cards only report a product name
+	if name := readSysString(base + "device/name"); name != "" {
+		return name
+	}
+	if level := readSysString(base + "md/level"); level != "" {
+		return "Linux software RAID (" + level + ")"
+	}
+	if pathExists(base + "comp_algorithm") {
+		return "zram compressed RAM disk"
+	}
+	return "N/A"
+}
+
+// driveTransport works out the bus a drive hangs off from its sysfs device path
+func driveTransport(dev string) string {
+	base := "/sys/block/" + dev + "/"
+	if nvmeTransport := readSysString(base + "device/transport"); nvmeTransport != "" {
+		if nvmeTransport == "pcie" {
+			return "NVMe"
+		}
+		return "NVMe over " + strings.ToUpper(nvmeTransport)
+	}
+	realPath, _ := filepath.EvalSymlinks(base)
+	// USB first: a USB-SATA bridge still shows a SCSI host underneath
+	switch {
+	case strings.Contains(realPath, "/usb"):
+		return "USB"
+	case strings.Contains(realPath, "/virtio"):
+		return "virtio"
+	case strings.Contains(realPath, "/mmc_host/"):
+		return "MMC/SD"
+	case strings.Contains(realPath, "/vbd-"):
+		return "Xen"
+	case strings.Contains(realPath, "/ata"):
+		return "SATA"
+	case pathExists(base + "md"):
+		return "md"
+	case pathExists(base + "comp_algorithm"):
+		return "zram"
+	case pathExists(base + "device/scsi_level"):
+		return "SCSI"
+	}
+	return "Unknown"
+}
+
+// Kind says what sort of device this is. Non-rotational alone doesn't mean
+// SSD: md arrays, zram and paravirtual disks all report rotational=0.
+func (d Drive) Kind() string {
+	switch {
+	case d.Transport == "md":
+		return "RAID"
+	case d.Transport == "zram" || d.Transport == "virtio" || d.Transport == "Xen" || strings.HasPrefix(d.Name, "loop"):
+		return "Virtual"
+	case d.Rotational:
+		return "HDD"
+	case d.Transport == "MMC/SD":
+		return "Flash"
+	}
+	return "SSD"
+}
+
+// activeScheduler picks the bracketed entry out of "mq-deadline kyber [bfq] none"
+func activeScheduler(s string) string {
+	if start, end := strings.Index(s, "["), strings.Index(s, "]"); start >= 0 && end > start {
+		return s[start+1 : end]
+	}
+	return s
+}
+
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func GetDriveInfo(dev string) Drive {
+	base := fmt.Sprintf("/sys/block/%s/", dev)
+	devicePath := base + "device/"
+	d := Drive{
+		Name:       dev,
+		Model:      GetDriveModel(dev),
+		Serial:     readSysString(devicePath + "serial"),
+		Firmware:   readSysString(devicePath + "firmware_rev"),
+		Transport:  driveTransport(dev),
+		Rotational: readSysString(base+"queue/rotational") == "1",
+		Removable:  readSysString(base+"removable") == "1",
+		Scheduler:  activeScheduler(readSysString(base + "queue/scheduler")),
+	}
+	if sectors, err := strconv.ParseUint(readSysString(base+"size"), 10, 64); err == nil {
+		d.Bytes = sectors * 512
+	}
+	d.LogicalSectorSize, _ = strconv.Atoi(readSysString(base + "queue/logical_block_size"))
+	d.PhysicalSectorSize, _ = strconv.Atoi(readSysString(base + "queue/physical_block_size"))
+	if d.Serial == "" {
+		// virtio exposes it on the disk itself, SCSI/SATA in VPD page 0x80
+		d.Serial = readSysString(base + "serial")
+	}
+	if d.Serial == "" {
+		d.Serial = readVPDSerial(devicePath + "vpd_pg80")
+	}
+	if d.Firmware == "" {
+		// SCSI/SATA devices expose the firmware revision as rev
+		d.Firmware = readSysString(devicePath + "rev")
+	}
+
+	if !strings.HasPrefix(d.Transport, "NVMe") {
+		return d
+	}
+	// /sys/block/nvme0n1/device points at the controller, /sys/class/nvme/nvme0
+	ctrlPath, err := filepath.EvalSymlinks(devicePath)
+	if err != nil {
+		return d
+	}
+	if entries, err := os.ReadDir(ctrlPath); err == nil {
+		for _, e := range entries {
+			if nvmeNamespaceRe.MatchString(e.Name()) {
+				d.Namespaces = append(d.Namespaces, e.Name())
+			}
+		}
+	}
+	pciPath := filepath.Join(ctrlPath, "device") + "/"
+	d.LinkSpeed = readSysString(pciPath + "current_link_speed")
+	d.MaxLinkSpeed = readSysString(pciPath + "max_link_speed")
+	d.LinkWidth, _ = strconv.Atoi(readSysString(pciPath + "current_link_width"))
+	d.MaxLinkWidth, _ = strconv.Atoi(readSysString(pciPath + "max_link_width"))
+	return d
+}
+
+// LinkDegraded reports whether the PCIe link trained below what the drive supports
+func (d Drive) LinkDegraded() bool {
+	if d.LinkWidth > 0 && d.MaxLinkWidth > 0 && d.LinkWidth < d.MaxLinkWidth {
+		return true
+	}
+	cur, maxSpeed := parseLinkSpeed(d.LinkSpeed), parseLinkSpeed(d.MaxLinkSpeed)
+	return cur > 0 && maxSpeed > 0 && cur < maxSpeed
+}
+
+// parseLinkSpeed turns "16.0 GT/s PCIe" into 16.0
+func parseLinkSpeed(s string) float64 {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return 0
+	}
+	v, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+func readSysString(path string) string {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return "N/A"
+		return ""
 	}
 	return strings.TrimSpace(string(data))
 }
+
+// readVPDSerial pulls the unit serial number out of the SCSI VPD page 0x80
+func readVPDSerial(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) < 4 {
+		return ""
+	}
+	length := int(data[2])<<8 | int(data[3])
+	if 4+length > len(data) {
+		length = len(data) - 4
+	}
+	return strings.TrimSpace(string(data[4 : 4+length]))
+}
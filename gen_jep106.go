@@ -0,0 +1,106 @@
+//go:build ignore
+
+// gen_jep106 rewrites jep106.txt from OpenOCD's src/helper/jep106.inc, which
+// tracks the JEDEC JEP106 publication bank by bank.
+//
+//	go generate                       # fetch the current list
+//	go run gen_jep106.go jep106.inc   # or convert a local copy
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const sourceURL = "https://raw.githubusercontent.com/openocd-org/openocd/master/src/helper/jep106.inc"
+
+// Entries look like: [0][0x01 - 1] = "AMD",
+var entryRe = regexp.MustCompile(`^\s*\[(\d+)\]\[0x([0-9a-fA-F]+) - 1\]\s*=\s*("(?:[^"\\]|\\.)*")`)
+var revisionRe = regexp.MustCompile(`JEP106[A-Z]+`)
+
+func main() {
+	src, err := open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer src.Close()
+
+	type entry struct {
+		bank, id int
+		name     string
+	}
+	var entries []entry
+	revision := ""
+	scanner := bufio.NewScanner(src)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if revision == "" {
+			revision = revisionRe.FindString(line)
+		}
+		m := entryRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		bank, _ := strconv.Atoi(m[1])
+		id, _ := strconv.ParseUint(m[2], 16, 8)
+		name, err := strconv.Unquote(m[3])
+		if err != nil || id == 0 || id > 0x7E {
+			continue
+		}
+		entries = append(entries, entry{bank + 1, int(id), name})
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stderr, "no JEP106 entries found")
+		os.Exit(1)
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# JEP106 manufacturer identification codes, as used in SMBIOS and SPD.\n")
+	fmt.Fprintf(&out, "# Generated by gen_jep106.go from OpenOCD's jep106.inc")
+	if revision != "" {
+		fmt.Fprintf(&out, " (%s)", revision)
+	}
+	fmt.Fprintf(&out, "; do not edit.\n#\n")
+	fmt.Fprintf(&out, "# Format:\n")
+	fmt.Fprintf(&out, "#   bank N    start of bank N (N-1 continuation codes, 0x7F, precede the ID)\n")
+	fmt.Fprintf(&out, "#   id name   7-bit ID in hex, without the odd-parity bit\n")
+	bank := 0
+	for _, e := range entries {
+		if e.bank != bank {
+			bank = e.bank
+			fmt.Fprintf(&out, "\nbank %d\n", bank)
+		}
+		fmt.Fprintf(&out, "%02x\t%s\n", e.id, e.name)
+	}
+	if err := os.WriteFile("jep106.txt", []byte(out.String()), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("wrote %d entries, banks 1-%d\n", len(entries), bank)
+}
+
+func open() (io.ReadCloser, error) {
+	if len(os.Args) > 1 {
+		return os.Open(os.Args[1])
+	}
+	resp, err := http.Get(sourceURL)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("fetching %s: %s", sourceURL, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+)
+
+// Kernel uevents are multicast on group 1; udev's rebroadcasts use group 2
+const ueventKernelGroup = 1
+
+type UEvent struct {
+	Action    string
+	Subsystem string
+	DevName   string
+	DevType   string
+	DevPath   string
+}
+
+// WatchBlockDevices listens on the kobject uevent netlink socket and forwards
+// every block subsystem event
+func WatchBlockDevices(events chan<- UEvent) error {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, syscall.NETLINK_KOBJECT_UEVENT)
+	if err != nil {
+		return err
+	}
+	addr := &syscall.SockaddrNetlink{
+		Family: syscall.AF_NETLINK,
+		Groups: ueventKernelGroup,
+		Pid:    0,
+	}
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return err
+	}
+	go func() {
+		defer syscall.Close(fd)
+		buf := make([]byte, 64*1024)
+		for {
+			n, _, err := syscall.Recvfrom(fd, buf, 0)
+			if err == syscall.EINTR {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			if ev, ok := ParseUEvent(buf[:n]); ok && ev.Subsystem == "block" {
+				events <- ev
+			}
+		}
+	}()
+	return nil
+}
+
+// ParseUEvent decodes a kernel uevent: an "action@devpath" header followed by
+// NUL separated KEY=value pairs
+func ParseUEvent(msg []byte) (UEvent, bool) {
+	parts := bytes.Split(msg, []byte{0})
+	if len(parts) < 2 || !bytes.Contains(parts[0], []byte("@")) {
+		return UEvent{}, false
+	}
+	var ev UEvent
+	for _, p := range parts[1:] {
+		key, value, ok := bytes.Cut(p, []byte("="))
+		if !ok {
+			continue
+		}
+		switch string(key) {
+		case "ACTION":
+			ev.Action = string(value)
+		case "SUBSYSTEM":
+			ev.Subsystem = string(value)
+		case "DEVNAME":
+			ev.DevName = string(value)
+		case "DEVTYPE":
+			ev.DevType = string(value)
+		case "DEVPATH":
+			ev.DevPath = string(value)
+		}
+	}
+	return ev, ev.Action != ""
+}
+
+// WatchMounts signals on changed every time the mount table changes. The
+// kernel flags /proc/self/mountinfo with POLLPRI on mount and umount.
+func WatchMounts(changed chan<- struct{}) error {
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return err
+	}
+	epfd, err := syscall.EpollCreate1(syscall.EPOLL_CLOEXEC)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	ev := syscall.EpollEvent{Events: syscall.EPOLLPRI | syscall.EPOLLERR, Fd: int32(f.Fd())}
+	if err := syscall.EpollCtl(epfd, syscall.EPOLL_CTL_ADD, int(f.Fd()), &ev); err != nil {
+		syscall.Close(epfd)
+		f.Close()
+		return err
+	}
+	go func() {
+		defer f.Close()
+		defer syscall.Close(epfd)
+		events := make([]syscall.EpollEvent, 1)
+		for {
+			n, err := syscall.EpollWait(epfd, events, -1)
+			if err == syscall.EINTR {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			if n > 0 {
+				changed <- struct{}{}
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	_ "embed"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// jep106.txt is regenerated from OpenOCD's copy of the JEDEC list, see gen_jep106.go
+//
+//go:generate go run gen_jep106.go
+//go:embed jep106.txt
+var jep106Data string
+
+type jep106Key struct {
+	bank byte // 1-based
+	id   byte // 7-bit, parity stripped
+}
+
+var jep106Names = parseJEP106(jep106Data)
+
+// parseJEP106 reads the embedded "bank N" / "id name" table
+func parseJEP106(data string) map[jep106Key]string {
+	names := map[jep106Key]string{}
+	var bank byte
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "bank "); ok {
+			n, err := strconv.Atoi(strings.TrimSpace(rest))
+			if err != nil {
+				bank = 0
+				continue
+			}
+			bank = byte(n)
+			continue
+		}
+		id, name, ok := strings.Cut(line, "\t")
+		if !ok || bank == 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(id, 16, 8)
+		if err != nil {
+			continue
+		}
+		names[jep106Key{bank, byte(v)}] = strings.TrimSpace(name)
+	}
+	return names
+}
+
+// JEP106Name decodes a manufacturer ID in the two-byte form used by SMBIOS and
+// SPD: the number of continuation codes (bank - 1) and the ID within that bank,
+// both with the odd-parity bit in bit 7. It returns "" for unknown IDs and for
+// bytes whose parity doesn't check out, which usually means garbage.
+func JEP106Name(continuation, id byte) string {
+	if !oddParity(continuation) || !oddParity(id) {
+		return ""
+	}
+	return jep106Names[jep106Key{bank: continuation&0x7F + 1, id: id & 0x7F}]
+}
+
+func oddParity(b byte) bool {
+	return bits.OnesCount8(b)%2 == 1
+}
+
+// decodeJEP106Hex handles manufacturer strings firmware fills with the raw ID,
+// e.g. "80CE" (Samsung) or "04CD" (G.Skill), continuation byte first
+func decodeJEP106Hex(s string) (string, bool) {
+	if len(s) != 4 {
+		return "", false
+	}
+	v, err := strconv.ParseUint(s, 16, 16)
+	if err != nil {
+		return "", false
+	}
+	name := JEP106Name(byte(v>>8), byte(v))
+	return name, name != ""
+}
@@ -0,0 +1,101 @@
+package main
+
+import "testing"
+
+// Only vendors whose names are stable across JEP106 revisions are asserted, so
+// the tests keep passing when jep106.txt is regenerated
+
+func TestJEP106Name(t *testing.T) {
+	tests := []struct {
+		name             string
+		continuation, id byte
+		want             string
+	}{
+		{"bank 1", 0x80, 0x01, "AMD"},
+		{"bank 1 with parity bit", 0x80, 0xCE, "Samsung"},
+		{"bank 2", 0x01, 0x98, "Kingston"},
+		{"bank 3", 0x02, 0x9E, "Corsair"},
+		{"bad continuation parity", 0x00, 0xCE, ""},
+		{"bad ID parity", 0x80, 0x4E, ""},
+		{"bank out of range", 0x7F, 0x01, ""},
+		{"continuation code is not an ID", 0x80, 0x7F, ""},
+		{"zero ID", 0x80, 0x80, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JEP106Name(tt.continuation, tt.id); got != tt.want {
+				t.Errorf("JEP106Name(%#02x, %#02x) = %q, want %q", tt.continuation, tt.id, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJEP106Hex(t *testing.T) {
+	tests := []struct {
+		in     string
+		want   string
+		wantOK bool
+	}{
+		{"80CE", "Samsung", true},
+		{"80ce", "Samsung", true},
+		{"8089", "Intel", true},
+		{"029E", "Corsair", true},
+		{"00CE", "", false}, // continuation byte without its parity bit
+		{"7F01", "", false}, // bank 128
+		{"807F", "", false},
+		{"80C", "", false},
+		{"ZZZZ", "", false},
+		{"Samsung", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, ok := decodeJEP106Hex(tt.in)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("decodeJEP106Hex(%q) = %q, %v, want %q, %v", tt.in, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestMapManufacturer(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		moduleID uint16
+		want     string
+	}{
+		{"SMBIOS hex, bank 1", "80CE", 0, "Samsung"},
+		{"SMBIOS hex, bank 2", "0198", 0, "Kingston"},
+		{"SMBIOS hex, bank 3", "029E", 0, "Corsair"},
+		{"plain name", "Kingston", 0, "Kingston"},
+		{"plain name wins over module ID", "Micron Technology", 0x9E02, "Micron Technology"},
+		{"placeholder falls back to module ID", "Unknown", 0x9E02, "Corsair"},
+		{"empty falls back to module ID", "", 0x9801, "Kingston"},
+		{"zero padded placeholder", "0000000000000000", 0, "Unknown"},
+		{"module ID with bad parity", "Unknown", 0x4E00, "Unknown"},
+		{"nothing at all", "", 0, "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mapManufacturer(tt.code, tt.moduleID); got != tt.want {
+				t.Errorf("mapManufacturer(%q, %#04x) = %q, want %q", tt.code, tt.moduleID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseJEP106(t *testing.T) {
+	names := parseJEP106("# comment\n\nbank 1\n01\tAMD\n\nbank 12\n2a\tSomebody\nbogus line\nzz\tBad ID\n")
+	want := map[jep106Key]string{
+		{1, 0x01}:  "AMD",
+		{12, 0x2A}: "Somebody",
+	}
+	if len(names) != len(want) {
+		t.Fatalf("got %d entries, want %d: %v", len(names), len(want), names)
+	}
+	for k, v := range want {
+		if names[k] != v {
+			t.Errorf("names[%v] = %q, want %q", k, names[k], v)
+		}
+	}
+}
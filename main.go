@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"image/color"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -16,7 +18,9 @@ import (
 )
 
 func main() {
-	fanLabelMap := loadConfig()
+	cfg := loadConfig()
+	fanLabelMap := cfg.FanLabels
+	useSIUnits = strings.EqualFold(cfg.SizeUnits, "si")
 
 	// Gather GPU info first, before any Fyne code
 	gpuInfo := GetGPUInfo()
@@ -45,52 +49,96 @@ func main() {
 	bg := canvas.NewRectangle(&color.RGBA{R: 30, G: 30, B: 40, A: 255}) // dark blue-gray
 	bg.Resize(fyne.NewSize(800, 600))
 
+	notices := NewNoticeBar()
+	cpuControl := NewCPUControlPanel(notices, cfg.RevertCPUSettings == nil || *cfg.RevertCPUSettings)
+
 	w.SetContent(container.NewStack(
 		bg,
 		container.NewVBox(
+			notices.Object(),
 			widget.NewSeparator(),
 			scroll,
 		),
 	))
 
-	refresh := func() {
+	ioSampler := NewDiskIOSampler()
+	cpuSampler := NewCPUUsageSampler()
+	topology := GetCPUTopology()
+	// SPD EEPROMs sit on a slow SMBus, read them once
+	spdModules := GetSPDModules()
+	memArray := GetMemoryArray()
+	// Mitigation state only changes with a reboot
+	cpuVulns := GetCPUVulnerabilities()
+	throttleMonitor := NewThrottleMonitor()
+	powerStates := NewPowerStateSampler()
+	// GPU throttle reasons seen on the previous refresh, to only announce new ones
+	gpuThrottled := map[string]bool{}
+	// Degraded member count per md array as of the previous refresh
+	raidDegraded := map[string]int{}
+	// Only rescanned when a block device or mount changes, see the watchers below
+	storage := ScanStorage()
+
+	coreSort := CoreTableSort{Column: coreColumnCore}
+
+	var refresh func()
+	refresh = func() {
 		sensor := readSensors()
 		moboTemps, cpuTemps, gpuTemps, _, _, gpuFans, moboFans := categorizeSensors(sensor)
 
-		cpuModel, cpuMHz, _, _ := GetCPUInfo()
-		// Count threads from /proc/cpuinfo
-		threadCountDisplay := "N/A"
-		if data, err := os.ReadFile("/proc/cpuinfo"); err == nil {
-			lines := strings.Split(string(data), "\n")
-			count := 0
-			for _, line := range lines {
-				if strings.HasPrefix(line, "processor") {
-					count++
-				}
-			}
-			threadCountDisplay = fmt.Sprintf("%d", count)
-		}
-		drives := GetDrives()
+		cpuInfo := GetCPUInfo(topology)
 		_, boardDMI, biosDMI, _, _ := GetDMIInfo()
 
+		driveTemps := GetDriveTemps()
+		diskIO := ioSampler.Sample(storage.Names())
+
 		driveCards := []fyne.CanvasObject{}
-		for _, d := range drives {
-			model := GetDriveModel(d)
-			treeRows := BuildPartitionTree(d, "")
-			driveCards = append(driveCards, widget.NewCard(
-				fmt.Sprintf("Drive: %s", d),
-				fmt.Sprintf("Model: %s", model),
-				widget.NewLabel(strings.Join(treeRows, "\n")),
-			))
+		for _, d := range storage.Drives {
+			var health *DriveHealth
+			if h, err := GetDriveHealth(d); err == nil {
+				health = &h
+			}
+			var io *DiskIO
+			if sample, ok := diskIO[d.Name]; ok {
+				io = &sample
+			}
+			treeRows := BuildPartitionTree(storage.Stacks[d.Name], storage.Mounts, "")
+			driveCards = append(driveCards, MakeDriveCard(d, treeRows, driveTemps[d.Name], health, io))
+		}
+
+		raidArrays := GetRAIDArrays()
+		for _, a := range raidArrays {
+			if a.Degraded > raidDegraded[a.Name] {
+				notices.Alert(fmt.Sprintf("RAID array %s is degraded: %d of %d members missing", a.Name, a.Degraded, a.RaidDisks), 30*time.Second)
+			}
+			raidDegraded[a.Name] = a.Degraded
 		}
 
 		// Show per-core speeds only
-		coreSpeeds := strings.Split(cpuMHz, ",")
-		var speedRows []string
-		for i, spd := range coreSpeeds {
-			speedRows = append(speedRows, fmt.Sprintf("Core %d: %s MHz", i, spd))
+		coreSpeeds := strings.Split(cpuInfo.MHz, ",")
+		freqs := GetCPUFreqs()
+		cpuTotal, cpuUsage, usageOK := cpuSampler.Sample()
+		var cpuLoadRows []fyne.CanvasObject
+		if len(freqs) == 0 && topology.Threads == 0 {
+			// No cpufreq driver or topology; fall back to /proc/cpuinfo
+			var speedLines []string
+			for i, spd := range coreSpeeds {
+				speedLines = append(speedLines, fmt.Sprintf("Core %d: %s MHz", i, spd))
+			}
+			cpuLoadRows = append(cpuLoadRows, widget.NewLabel(strings.Join(speedLines, "\n")))
+		}
+		if !usageOK {
+			cpuUsage = nil
+		}
+		if topology.Threads > 0 {
+			stats := BuildCoreStats(topology, freqs, cpuInfo.ThreadMHz, cpuUsage, GetCoreTemps())
+			cpuLoadRows = append(cpuLoadRows, MakeCoreTable(stats, &coreSort, 80, refresh))
+		} else {
+			cpuLoadRows = append(cpuLoadRows, cpuRows(freqs, cpuUsage)...)
+		}
+		cpuLoadRows = append(cpuLoadRows, cpuPolicyRows(freqs)...)
+		if usageOK {
+			cpuLoadRows = append(cpuLoadRows, cpuTotalRow(cpuTotal))
 		}
-		coreCountDisplay := fmt.Sprintf("%d", len(coreSpeeds))
 		// RAM Info Card
 		ramBanks, ramErr := GetRAMBanks()
 		var ramRows []fyne.CanvasObject
@@ -101,13 +149,28 @@ func main() {
 			ramRows = append(ramRows, widget.NewLabel("No RAM banks found"))
 		} else {
 			for i, bank := range ramBanks {
+				if !bank.Populated {
+					txt := canvas.NewText(fmt.Sprintf("Bank #%d  %s: empty", i+1, bank.Locator), color.Gray{Y: 150})
+					txt.TextSize = 13
+					txt.TextStyle = fyne.TextStyle{Monospace: true}
+					ramRows = append(ramRows, txt)
+					continue
+				}
 				ramInfoLines := []string{
 					fmt.Sprintf("Bank #%d", i+1),
 					fmt.Sprintf("  Locator: %s", bank.Locator),
 					fmt.Sprintf("  Size: %d MB ", bank.SizeMB),
-					fmt.Sprintf("  Speed: %d MHz", bank.SpeedMHz),
-					fmt.Sprintf("  Type: %s", bank.MemoryType),
-					fmt.Sprintf("  Manufacturer: %s", bank.Manufacturer),
+					fmt.Sprintf("  %s", bank.Summary()),
+				}
+				if bank.FormFactor != "" || len(bank.TypeDetail) > 0 {
+					ramInfoLines = append(ramInfoLines, fmt.Sprintf("  Form: %s", strings.TrimSpace(bank.FormFactor+" "+strings.Join(bank.TypeDetail, ", "))))
+				}
+				if bank.MinVoltageMV > 0 && bank.MaxVoltageMV > 0 && bank.MinVoltageMV != bank.MaxVoltageMV {
+					ramInfoLines = append(ramInfoLines, fmt.Sprintf("  Voltage: %d-%d mV, configured %d mV", bank.MinVoltageMV, bank.MaxVoltageMV, bank.ConfiguredVoltageMV))
+				}
+				ramInfoLines = append(ramInfoLines, fmt.Sprintf("  Manufacturer: %s", bank.Manufacturer))
+				if bank.Serial != "" {
+					ramInfoLines = append(ramInfoLines, fmt.Sprintf("  Serial: %s", bank.Serial))
 				}
 				totalBankSize += bank.SizeMB
 				// Use canvas.Text for compact, non-padded rendering
@@ -125,7 +188,15 @@ func main() {
 			}
 		}
 		totalRamLine := fmt.Sprintf("Total RAM: %d MB", totalBankSize)
-		ramRows = append([]fyne.CanvasObject{widget.NewLabel(totalRamLine)}, ramRows...)
+		header := []fyne.CanvasObject{widget.NewLabel(totalRamLine)}
+		if summary := UpgradeSummary(ramBanks, memArray); summary != "" {
+			header = append(header, widget.NewLabelWithStyle(summary, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
+		}
+		if mem, err := GetMemInfo(); err == nil {
+			header = append(header, memoryRows(mem, uint64(totalBankSize))...)
+		}
+		ramRows = append(header, ramRows...)
+		ramRows = append(ramRows, spdRows(spdModules)...)
 		ramCard := widget.NewCard("RAM Info", "", container.NewVBox(ramRows...))
 
 		// Gather GPU VBIOS version (only one per GPU)
@@ -148,10 +219,14 @@ func main() {
 
 		sysCards := []fyne.CanvasObject{
 			widget.NewCard("CPU Info", "", container.NewVBox(
-				widget.NewLabel(fmt.Sprintf("Model: %s", cpuModel)),
-				widget.NewLabel(strings.Join(speedRows, "\n")),
-				widget.NewLabel(fmt.Sprintf("Cores: %s  Threads: %s", coreCountDisplay, threadCountDisplay)),
+				widget.NewLabel(fmt.Sprintf("Model: %s", cpuInfo.Model)),
+				container.NewVBox(cpuLoadRows...),
+				widget.NewLabel(fmt.Sprintf("Cores: %s  Threads: %s", cpuInfo.Cores, cpuInfo.Threads)),
+				container.NewVBox(topologyRows(topology)...),
+				cpuControl.Object(),
 			)),
+			MakePowerStatesCard(powerStates.Sample()),
+			MakeCPUSecurityCard(cpuInfo, cpuVulns),
 			widget.NewCard("Motherboard Info (DMI)", "", widget.NewLabel(boardDMI)),
 			widget.NewCard("BIOS Info (DMI)", "", widget.NewLabel(biosDMI)),
 			widget.NewCard("GPU Info", "", widget.NewLabel(
@@ -233,6 +308,22 @@ func main() {
 			}
 		}
 
+		// Catch throttling that happened between refreshes, not just what's visible now
+		throttleCounters, throttleEvents := throttleMonitor.Update(topology)
+		if len(throttleEvents) > 0 {
+			notices.Alert("CPU "+strings.Join(throttleEvents, ", "), 10*time.Second)
+		}
+		gpuThrottleReasons := GetGPUThrottleReasons()
+		for _, card := range sortedCards(gpuThrottleReasons) {
+			if !gpuThrottled[card] {
+				notices.Alert(fmt.Sprintf("GPU %s throttling: %s", card, strings.Join(gpuThrottleReasons[card], ", ")), 10*time.Second)
+			}
+		}
+		gpuThrottled = map[string]bool{}
+		for card := range gpuThrottleReasons {
+			gpuThrottled[card] = true
+		}
+
 		cardColor := &color.RGBA{R: 60, G: 60, B: 80, A: 255} // lighter blue-gray for cards
 
 		// Wrap each card in a colored background
@@ -243,6 +334,14 @@ func main() {
 		}
 
 		driveCardsWrapped := []fyne.CanvasObject{}
+		if len(raidArrays) > 0 {
+			driveCardsWrapped = append(driveCardsWrapped, wrapCard(MakeRAIDCard(raidArrays)))
+		}
+		btrfsFilesystems := GetBtrfsFilesystems(storage.Mounts)
+		zfsInfo := GetZFSInfo()
+		if len(btrfsFilesystems) > 0 || (zfsInfo != nil && len(zfsInfo.Pools) > 0) {
+			driveCardsWrapped = append(driveCardsWrapped, wrapCard(MakePoolsCard(btrfsFilesystems, zfsInfo)))
+		}
 		for _, c := range driveCards {
 			driveCardsWrapped = append(driveCardsWrapped, wrapCard(c))
 		}
@@ -262,9 +361,13 @@ func main() {
 			),
 			container.NewVBox(
 				wrapCard(MakeSection("Motherboard Temp", filteredMoboTemps, moboFans, 60, false, fanLabelMap)),
-				wrapCard(MakeSection("CPU Temp", cpuTemps, nil, 80, false, fanLabelMap)),
+				wrapCard(container.NewVBox(append(
+					[]fyne.CanvasObject{MakeSection("CPU Temp", cpuTemps, nil, 80, false, fanLabelMap)},
+					throttleRows(throttleCounters)...)...)),
 				wrapCard(MakeSection("Fans", nil, caseFans, 0, false, fanLabelMap)),
-				wrapCard(MakeSection("GPU Temp & Fan", filteredGPUTemps, gpuFans, 80, false, fanLabelMap)),
+				wrapCard(container.NewVBox(append(
+					[]fyne.CanvasObject{MakeSection("GPU Temp & Fan", filteredGPUTemps, gpuFans, 80, false, fanLabelMap)},
+					gpuThrottleRows(gpuThrottleReasons)...)...)),
 			),
 		)
 
@@ -279,6 +382,68 @@ func main() {
 
 	refresh()
 
+	// Rebuild the storage view as soon as drives come and go or something is mounted
+	blockEvents := make(chan UEvent, 16)
+	mountEvents := make(chan struct{}, 1)
+	if err := WatchBlockDevices(blockEvents); err != nil {
+		fmt.Printf("Hotplug detection unavailable: %v\n", err)
+	}
+	if err := WatchMounts(mountEvents); err != nil {
+		fmt.Printf("Mount change detection unavailable: %v\n", err)
+	}
+	go func() {
+		// Plugging in a disk sends a burst of uevents (disk, each partition,
+		// dm mappings, mounts); wait until they settle and rescan once
+		const settle = 250 * time.Millisecond
+		const maxDelay = 2 * time.Second
+		for {
+			var batch []UEvent
+			select {
+			case ev := <-blockEvents:
+				batch = append(batch, ev)
+			case <-mountEvents:
+			}
+			quiet := time.NewTimer(settle)
+			deadline := time.After(maxDelay)
+		collect:
+			for {
+				select {
+				case ev := <-blockEvents:
+					batch = append(batch, ev)
+					quiet.Reset(settle)
+				case <-mountEvents:
+					quiet.Reset(settle)
+				case <-quiet.C:
+					break collect
+				case <-deadline:
+					break collect
+				}
+			}
+			quiet.Stop()
+			fyne.Do(func() {
+				previous := storage
+				storage = ScanStorage()
+				// Partition and dm events arrive too; only whole disks get a notice
+				for _, ev := range batch {
+					if ev.DevType != "disk" {
+						continue
+					}
+					switch ev.Action {
+					case "add":
+						if d, ok := storage.Drive(ev.DevName); ok {
+							notices.Show(driveNotice("connected", d), 5*time.Second)
+						}
+					case "remove":
+						if d, ok := previous.Drive(ev.DevName); ok {
+							notices.Show(driveNotice("disconnected", d), 5*time.Second)
+						}
+					}
+				}
+				refresh()
+			})
+		}
+	}()
+
 	go func() {
 		ticker := time.NewTicker(time.Second)
 		for range ticker.C {
@@ -287,5 +452,17 @@ func main() {
 			})
 		}
 	}()
+
+	// On Ctrl+C/SIGTERM quit through Fyne so the revert below runs on this goroutine
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		fyne.Do(a.Quit)
+	}()
 	w.ShowAndRun()
+	// Put back any CPU settings changed from the control panel
+	if cpuControl.RevertOnExit {
+		RevertCPUSettings()
+	}
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// MemInfo holds the /proc/meminfo fields we show, converted to bytes
+type MemInfo struct {
+	Total          uint64
+	Available      uint64
+	Cached         uint64
+	Buffers        uint64
+	Dirty          uint64
+	Shmem          uint64
+	Slab           uint64
+	SwapTotal      uint64
+	SwapFree       uint64
+	HugePagesTotal uint64
+	HugePagesFree  uint64
+	HugePageSize   uint64
+}
+
+func (m MemInfo) Used() uint64 {
+	if m.Available > m.Total {
+		return 0
+	}
+	return m.Total - m.Available
+}
+
+func (m MemInfo) SwapUsed() uint64 {
+	if m.SwapFree > m.SwapTotal {
+		return 0
+	}
+	return m.SwapTotal - m.SwapFree
+}
+
+func GetMemInfo() (MemInfo, error) {
+	data, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return MemInfo{}, err
+	}
+	return parseMemInfo(data), nil
+}
+
+// parseMemInfo reads "Key:   value kB" lines; HugePages_* are plain counts
+func parseMemInfo(data []byte) MemInfo {
+	var m MemInfo
+	fields := map[string]*uint64{
+		"MemTotal":        &m.Total,
+		"MemAvailable":    &m.Available,
+		"Cached":          &m.Cached,
+		"Buffers":         &m.Buffers,
+		"Dirty":           &m.Dirty,
+		"Shmem":           &m.Shmem,
+		"Slab":            &m.Slab,
+		"SwapTotal":       &m.SwapTotal,
+		"SwapFree":        &m.SwapFree,
+		"HugePages_Total": &m.HugePagesTotal,
+		"HugePages_Free":  &m.HugePagesFree,
+		"Hugepagesize":    &m.HugePageSize,
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		dst, ok := fields[key]
+		if !ok {
+			continue
+		}
+		parts := strings.Fields(rest)
+		if len(parts) == 0 {
+			continue
+		}
+		v, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(parts) > 1 && parts[1] == "kB" {
+			v *= 1024
+		}
+		*dst = v
+	}
+	return m
+}
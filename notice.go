@@ -0,0 +1,60 @@
+package main
+
+import (
+	"image/color"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+)
+
+// NoticeBar is a one-line banner for transient events such as drive hotplug.
+// Show must be called on the Fyne goroutine.
+type NoticeBar struct {
+	text       *canvas.Text
+	bg         *canvas.Rectangle
+	box        *fyne.Container
+	generation int
+}
+
+func NewNoticeBar() *NoticeBar {
+	text := canvas.NewText("", color.White)
+	text.TextStyle = fyne.TextStyle{Bold: true}
+	bg := canvas.NewRectangle(color.RGBA{R: 40, G: 90, B: 150, A: 255})
+	n := &NoticeBar{text: text, bg: bg, box: container.NewStack(bg, container.NewPadded(text))}
+	n.box.Hide()
+	return n
+}
+
+func (n *NoticeBar) Object() fyne.CanvasObject {
+	return n.box
+}
+
+// Show displays msg for d, replacing whatever notice is currently up
+func (n *NoticeBar) Show(msg string, d time.Duration) {
+	n.show(msg, d, color.RGBA{R: 40, G: 90, B: 150, A: 255})
+}
+
+// Alert is Show in red, for things that need attention
+func (n *NoticeBar) Alert(msg string, d time.Duration) {
+	n.show(msg, d, color.RGBA{R: 170, G: 20, B: 20, A: 255})
+}
+
+func (n *NoticeBar) show(msg string, d time.Duration, bg color.Color) {
+	n.bg.FillColor = bg
+	n.bg.Refresh()
+	n.generation++
+	gen := n.generation
+	n.text.Text = msg
+	n.text.Refresh()
+	n.box.Show()
+	time.AfterFunc(d, func() {
+		fyne.Do(func() {
+			// A newer notice owns the bar now
+			if n.generation == gen {
+				n.box.Hide()
+			}
+		})
+	})
+}
@@ -3,91 +3,221 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 )
 
-func BuildPartitionTree(dev string, indent string) []string {
-	sizePath := fmt.Sprintf("/sys/block/%s/size", dev)
-	sizeGB := ""
-	sizeRaw, err := os.ReadFile(sizePath)
-	if err == nil {
-		sectors, err := strconv.ParseInt(strings.TrimSpace(string(sizeRaw)), 10, 64)
-		if err == nil {
-			gb := float64(sectors*512) / 1024.0 / 1024.0 / 1024.0
-			sizeGB = fmt.Sprintf("%.2f GB", gb)
+// PartitionRow is one line of the partition tree; Usage is nil when nothing is mounted
+type PartitionRow struct {
+	Text  string
+	Usage *FSUsage
+}
+
+type FSUsage struct {
+	MountPoint string
+	FSType     string
+	Options    string
+	TotalBytes uint64
+	UsedBytes  uint64
+	FreeBytes  uint64
+	Inodes     uint64
+	InodesFree uint64
+}
+
+// UsedFraction matches df: used / (used + available to unprivileged users)
+func (u FSUsage) UsedFraction() float64 {
+	if u.UsedBytes+u.FreeBytes == 0 {
+		return 0
+	}
+	return float64(u.UsedBytes) / float64(u.UsedBytes+u.FreeBytes)
+}
+
+func (u FSUsage) InodeFraction() float64 {
+	if u.Inodes == 0 {
+		return 0
+	}
+	return float64(u.Inodes-u.InodesFree) / float64(u.Inodes)
+}
+
+type mountEntry struct {
+	Dev        string // major:minor
+	Root       string // subtree of the filesystem mounted here, "/" unless bind mounted
+	Source     string
+	MountPoint string
+	FSType     string
+	Options    string
+}
+
+func BuildPartitionTree(stack *BlockNode, mounts mountTable, indent string) []PartitionRow {
+	return blockNodeRows(stack, mounts, indent, indent)
+}
+
+// blockNodeRows flattens the stack into tree lines; childPrefix carries the
+// vertical guides of the ancestors down to the children
+func blockNodeRows(n *BlockNode, mounts mountTable, linePrefix, childPrefix string) []PartitionRow {
+	text := fmt.Sprintf("%s (%s)", n.Label, formatBytes(n.Bytes))
+	if n.Type != "disk" && n.Type != "part" {
+		text += " " + n.Type
+	}
+	if len(n.Slaves) > 1 {
+		text += " [" + strings.Join(n.Slaves, ", ") + "]"
+	}
+	row := PartitionRow{}
+	if ms := mounts.lookup(n); len(ms) > 0 {
+		var points []string
+		for _, m := range ms {
+			points = append(points, m.MountPoint)
 		}
+		text += " " + strings.Join(points, ", ")
+		row.Usage = GetFSUsage(ms[0])
 	}
-	rows := []string{fmt.Sprintf("%s%s (%s)", indent, dev, sizeGB)}
-	parts := GetPartitions(dev)
-	for _, p := range parts {
-		partSize, partMount := GetPartitionInfo(p)
-		partRow := fmt.Sprintf("%s├─%s (%s) %s", indent, p, partSize, partMount)
-		rows = append(rows, partRow)
-		mapperDir := "/dev/mapper/"
-		if files, err := os.ReadDir(mapperDir); err == nil {
-			for _, f := range files {
-				linkPath := mapperDir + f.Name()
-				linkTarget, err := os.Readlink(linkPath)
-				if err == nil && (strings.Contains(linkTarget, p) || strings.Contains(linkTarget, dev)) {
-					luksMount := ""
-					mounts, err := os.ReadFile("/proc/mounts")
-					if err == nil {
-						for _, line := range strings.Split(string(mounts), "\n") {
-							fields := strings.Fields(line)
-							if len(fields) >= 2 && strings.Contains(fields[0], f.Name()) {
-								luksMount = fields[1]
-								break
-							}
-						}
-					}
-					cryptRow := fmt.Sprintf("%s└─%s (LUKS) %s", indent+"  ", f.Name(), luksMount)
-					rows = append(rows, cryptRow)
-					break
-				}
-			}
+	row.Text = linePrefix + text
+	rows := []PartitionRow{row}
+	for i, c := range n.Children {
+		branch, guide := "├─", "│ "
+		if i == len(n.Children)-1 {
+			branch, guide = "└─", "  "
 		}
+		rows = append(rows, blockNodeRows(c, mounts, childPrefix+branch, childPrefix+guide)...)
 	}
 	return rows
 }
 
+// GetPartitions lists the partitions of dev: subdirectories of its sysfs
+// directory that carry a partition attribute
 func GetPartitions(dev string) []string {
 	parts := []string{}
-	files, _ := os.ReadDir(fmt.Sprintf("/sys/block/%s/", dev))
+	base := fmt.Sprintf("/sys/class/block/%s/", dev)
+	files, _ := os.ReadDir(base)
 	for _, f := range files {
-		if strings.HasPrefix(f.Name(), dev) {
+		if pathExists(base + f.Name() + "/partition") {
 			parts = append(parts, f.Name())
 		}
 	}
 	return parts
 }
 
-func GetPartitionInfo(part string) (sizeGB, mount string) {
-	sizePath := ""
-	if strings.HasPrefix(part, "nvme") {
-		sizePath = fmt.Sprintf("/sys/block/%s/%s/size", part[:6], part)
-	} else {
-		sizePath = fmt.Sprintf("/sys/block/%s/%s/size", part[:3], part)
-	}
-	sizeBytes := ""
-	sizeRaw, err := os.ReadFile(sizePath)
-	if err == nil {
-		sectors, err := strconv.ParseInt(strings.TrimSpace(string(sizeRaw)), 10, 64)
-		if err == nil {
-			gb := float64(sectors*512) / 1024.0 / 1024.0 / 1024.0
-			sizeBytes = fmt.Sprintf("%.2f GB", gb)
+// mountTable indexes /proc/self/mountinfo by the "major:minor" of the backing
+// device and by the symlink-resolved source path
+type mountTable struct {
+	byDev    map[string][]mountEntry
+	bySource map[string][]mountEntry
+	all      []mountEntry
+}
+
+// readMountInfo runs on storage rescans only, so the symlink resolution of
+// /dev/disk/by-* and /dev/mapper sources happens here rather than per refresh
+func readMountInfo() mountTable {
+	t := mountTable{byDev: map[string][]mountEntry{}, bySource: map[string][]mountEntry{}}
+	data, err := os.ReadFile("/proc/self/mountinfo")
+	if err != nil {
+		return t
+	}
+	resolved := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		m, ok := parseMountInfoLine(line)
+		if !ok {
+			continue
+		}
+		t.all = append(t.all, m)
+		t.byDev[m.Dev] = append(t.byDev[m.Dev], m)
+		if !strings.HasPrefix(m.Source, "/dev/") {
+			continue
+		}
+		path, seen := resolved[m.Source]
+		if !seen {
+			path, err = filepath.EvalSymlinks(m.Source)
+			if err != nil {
+				path = ""
+			}
+			resolved[m.Source] = path
+		}
+		if path != "" {
+			t.bySource[path] = append(t.bySource[path], m)
+		}
+	}
+	return t
+}
+
+// parseMountInfoLine handles the layout from proc(5):
+// 36 35 98:0 /mnt1 /mnt2 rw,noatime master:1 - ext3 /dev/root rw,errors=continue
+func parseMountInfoLine(line string) (mountEntry, bool) {
+	fields := strings.Fields(line)
+	// Optional fields run until the lone "-" separator
+	sep := -1
+	for i := 6; i < len(fields); i++ {
+		if fields[i] == "-" {
+			sep = i
+			break
 		}
 	}
-	mount = ""
-	mounts, err := os.ReadFile("/proc/mounts")
-	if err == nil {
-		for _, line := range strings.Split(string(mounts), "\n") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 && strings.Contains(fields[0], part) {
-				mount = fields[1]
-				break
+	if sep < 0 || sep+2 >= len(fields) {
+		return mountEntry{}, false
+	}
+	return mountEntry{
+		Dev:        fields[2],
+		Root:       unescapeMountField(fields[3]),
+		MountPoint: unescapeMountField(fields[4]),
+		Options:    fields[5],
+		FSType:     fields[sep+1],
+		Source:     unescapeMountField(fields[sep+2]),
+	}, true
+}
+
+// lookup returns every mount of the block device, primary mount first. btrfs
+// reports an anonymous device number, so those fall back to the source path.
+func (t mountTable) lookup(n *BlockNode) []mountEntry {
+	mounts := append([]mountEntry{}, t.byDev[n.Dev]...)
+	if len(mounts) == 0 {
+		mounts = append(mounts, t.bySource["/dev/"+n.Name]...)
+	}
+	// Prefer the mount of the filesystem root over bind mounts and subvolumes
+	sort.SliceStable(mounts, func(i, j int) bool {
+		return mounts[i].Root == "/" && mounts[j].Root != "/"
+	})
+	return mounts
+}
+
+// unescapeMountField decodes the \040 style octal escapes the kernel uses for spaces and tabs
+func unescapeMountField(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
 			}
 		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// GetFSUsage statfs()s the mount point; returns nil for pseudo filesystems without blocks
+func GetFSUsage(m mountEntry) *FSUsage {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(m.MountPoint, &st); err != nil || st.Blocks == 0 {
+		return nil
+	}
+	blockSize := uint64(st.Frsize)
+	if blockSize == 0 {
+		blockSize = uint64(st.Bsize)
+	}
+	return &FSUsage{
+		MountPoint: m.MountPoint,
+		FSType:     m.FSType,
+		Options:    m.Options,
+		TotalBytes: st.Blocks * blockSize,
+		UsedBytes:  (st.Blocks - st.Bfree) * blockSize,
+		FreeBytes:  st.Bavail * blockSize,
+		Inodes:     st.Files,
+		InodesFree: st.Ffree,
 	}
-	return sizeBytes, mount
 }
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type BtrfsFS struct {
+	UUID       string
+	Label      string
+	MountPoint string
+	Devices    []string
+	// Allocation profiles, e.g. raid1 for data and raid1c3 for metadata
+	DataProfile     string
+	MetadataProfile string
+	DataUsed        uint64
+	DataTotal       uint64
+	MetadataUsed    uint64
+	MetadataTotal   uint64
+	// Device error counters (write_errs, corruption_errs, ...) summed over all members
+	Errors map[string]uint64
+}
+
+func (b BtrfsFS) ErrorCount() uint64 {
+	var total uint64
+	for _, v := range b.Errors {
+		total += v
+	}
+	return total
+}
+
+type ZFSPool struct {
+	Name  string
+	State string
+}
+
+type ZFSInfo struct {
+	Pools     []ZFSPool
+	ARCSize   uint64
+	ARCMax    uint64
+	ARCHits   uint64
+	ARCMisses uint64
+}
+
+// ARCHitRate is cumulative since the module was loaded
+func (z ZFSInfo) ARCHitRate() float64 {
+	if z.ARCHits+z.ARCMisses == 0 {
+		return 0
+	}
+	return float64(z.ARCHits) / float64(z.ARCHits+z.ARCMisses)
+}
+
+// GetBtrfsFilesystems groups multi-device btrfs filesystems by their
+// /sys/fs/btrfs/<uuid> entry
+func GetBtrfsFilesystems(mounts mountTable) []BtrfsFS {
+	base := "/sys/fs/btrfs/"
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil
+	}
+	var result []BtrfsFS
+	for _, e := range entries {
+		fsPath := base + e.Name() + "/"
+		// Skip "features" and anything else that isn't a filesystem
+		if !pathExists(fsPath + "devices") {
+			continue
+		}
+		fs := BtrfsFS{
+			UUID:    e.Name(),
+			Label:   readSysString(fsPath + "label"),
+			Devices: listDir(fsPath + "devices"),
+			Errors:  map[string]uint64{},
+		}
+		fs.DataProfile, fs.DataUsed, fs.DataTotal = btrfsAllocation(fsPath + "allocation/data/")
+		fs.MetadataProfile, fs.MetadataUsed, fs.MetadataTotal = btrfsAllocation(fsPath + "allocation/metadata/")
+
+		statFiles, _ := filepath.Glob(fsPath + "devinfo/*/error_stats")
+		for _, f := range statFiles {
+			for name, v := range parseKeyValueLines(readSysString(f)) {
+				fs.Errors[name] += v
+			}
+		}
+
+		devSet := map[string]bool{}
+		for _, d := range fs.Devices {
+			devSet["/dev/"+d] = true
+		}
+		for _, m := range mounts.all {
+			if m.FSType != "btrfs" {
+				continue
+			}
+			if resolved, err := filepath.EvalSymlinks(m.Source); err == nil && devSet[resolved] {
+				fs.MountPoint = m.MountPoint
+				// Prefer the top of the filesystem over subvolume mounts
+				if m.Root == "/" {
+					break
+				}
+			}
+		}
+		result = append(result, fs)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Label < result[j].Label })
+	return result
+}
+
+// btrfsAllocation reads one block group type; the profile shows up as a subdirectory
+func btrfsAllocation(path string) (profile string, used, total uint64) {
+	used, _ = strconv.ParseUint(readSysString(path+"bytes_used"), 10, 64)
+	total, _ = strconv.ParseUint(readSysString(path+"total_bytes"), 10, 64)
+	entries, _ := os.ReadDir(path)
+	for _, e := range entries {
+		if e.IsDir() {
+			profile = e.Name()
+		}
+	}
+	return profile, used, total
+}
+
+// parseKeyValueLines reads "name value" lines as found in btrfs error_stats
+func parseKeyValueLines(data string) map[string]uint64 {
+	values := map[string]uint64{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values
+}
+
+// GetZFSInfo reads pool state and ARC statistics from the SPL kstats; nil when ZFS isn't loaded
+func GetZFSInfo() *ZFSInfo {
+	base := "/proc/spl/kstat/zfs/"
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil
+	}
+	info := &ZFSInfo{}
+	for _, e := range entries {
+		if state := readSysString(base + e.Name() + "/state"); state != "" {
+			info.Pools = append(info.Pools, ZFSPool{Name: e.Name(), State: state})
+		}
+	}
+	if data, err := os.ReadFile(base + "arcstats"); err == nil {
+		arc := parseKstat(string(data))
+		info.ARCSize = arc["size"]
+		info.ARCMax = arc["c_max"]
+		info.ARCHits = arc["hits"]
+		info.ARCMisses = arc["misses"]
+	}
+	return info
+}
+
+// parseKstat reads a named kstat: a header line, a "name type data" line, then one value per line
+func parseKstat(data string) map[string]uint64 {
+	values := map[string]uint64{}
+	lines := strings.Split(data, "\n")
+	if len(lines) < 2 {
+		return values
+	}
+	for _, line := range lines[2:] {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if v, err := strconv.ParseUint(fields[2], 10, 64); err == nil {
+			values[fields[0]] = v
+		}
+	}
+	return values
+}
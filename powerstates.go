@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StateShare is the fraction of session time spent in one idle state or frequency
+type StateShare struct {
+	Name     string
+	Fraction float64
+}
+
+// PowerStateSampler compares cpuidle and cpufreq time_in_state counters
+// against a baseline taken when it was created
+type PowerStateSampler struct {
+	start     time.Time
+	idleOrder []string
+	idleBase  map[string]uint64
+	freqBase  map[uint64]uint64
+	cpuCount  int
+}
+
+func NewPowerStateSampler() *PowerStateSampler {
+	s := &PowerStateSampler{start: time.Now()}
+	s.idleOrder, s.idleBase, s.cpuCount = readIdleTotals()
+	s.freqBase = readFreqTotals()
+	return s
+}
+
+// Sample returns C-state residency (including time not idle, as "C0") and
+// frequency residency since the sampler was created
+func (s *PowerStateSampler) Sample() (cstates []StateShare, freqs []StateShare) {
+	elapsedUs := float64(time.Since(s.start).Microseconds())
+	_, idle, cpus := readIdleTotals()
+	if elapsedUs > 0 && cpus > 0 && len(idle) > 0 {
+		budget := elapsedUs * float64(cpus)
+		idleSum := 0.0
+		for _, name := range s.idleOrder {
+			delta := float64(counterDelta(s.idleBase[name], idle[name]))
+			idleSum += delta
+			cstates = append(cstates, StateShare{Name: name, Fraction: delta / budget})
+		}
+		active := 1 - idleSum/budget
+		if active < 0 {
+			active = 0
+		}
+		cstates = append([]StateShare{{Name: "C0 (active)", Fraction: active}}, cstates...)
+	}
+
+	cur := readFreqTotals()
+	var total uint64
+	deltas := map[uint64]uint64{}
+	for freq, t := range cur {
+		d := counterDelta(s.freqBase[freq], t)
+		deltas[freq] = d
+		total += d
+	}
+	if total > 0 {
+		keys := make([]uint64, 0, len(deltas))
+		for k := range deltas {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+		for _, k := range keys {
+			freqs = append(freqs, StateShare{Name: fmt.Sprintf("%d MHz", k/1000), Fraction: float64(deltas[k]) / float64(total)})
+		}
+	}
+	return cstates, freqs
+}
+
+func counterDelta(a, b uint64) uint64 {
+	if b < a {
+		return 0
+	}
+	return b - a
+}
+
+// readIdleTotals sums cpuidle state residency (µs) by state name across all
+// CPUs, keeping the shallow to deep order of cpu0
+func readIdleTotals() (order []string, totals map[string]uint64, cpus int) {
+	totals = map[string]uint64{}
+	dirs, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpuidle")
+	for _, dir := range dirs {
+		states, _ := filepath.Glob(dir + "/state[0-9]*")
+		// state10 sorts before state2 lexically
+		sort.Slice(states, func(i, j int) bool {
+			a, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(states[i]), "state"))
+			b, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(states[j]), "state"))
+			return a < b
+		})
+		if len(states) > 0 {
+			cpus++
+		}
+		for _, st := range states {
+			name := readSysString(st + "/name")
+			t, err := strconv.ParseUint(readSysString(st+"/time"), 10, 64)
+			if name == "" || err != nil {
+				continue
+			}
+			if _, seen := totals[name]; !seen {
+				order = append(order, name)
+			}
+			totals[name] += t
+		}
+	}
+	return order, totals, cpus
+}
+
+// readFreqTotals sums cpufreq stats time_in_state (10ms units) per frequency across policies
+func readFreqTotals() map[uint64]uint64 {
+	totals := map[uint64]uint64{}
+	files, _ := filepath.Glob("/sys/devices/system/cpu/cpufreq/policy*/stats/time_in_state")
+	for _, f := range files {
+		for _, line := range strings.Split(readSysString(f), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) != 2 {
+				continue
+			}
+			freq, err1 := strconv.ParseUint(fields[0], 10, 64)
+			t, err2 := strconv.ParseUint(fields[1], 10, 64)
+			if err1 == nil && err2 == nil {
+				totals[freq] += t
+			}
+		}
+	}
+	return totals
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type RAIDMember struct {
+	Name  string
+	State string // comma separated md states: in_sync, faulty, spare, write_mostly, ...
+}
+
+// Healthy is true for active members and for idle hot spares; only faulty
+// members (and missing ones, counted in Degraded) need attention
+func (m RAIDMember) Healthy() bool {
+	if strings.Contains(m.State, "faulty") {
+		return false
+	}
+	return strings.Contains(m.State, "in_sync") || m.Spare()
+}
+
+func (m RAIDMember) Spare() bool {
+	return strings.Contains(m.State, "spare") && !strings.Contains(m.State, "faulty")
+}
+
+type RAIDArray struct {
+	Name       string
+	Level      string
+	State      string // array_state: clean, active, read-auto, inactive, ...
+	Status     string // the [UU_] member map from /proc/mdstat
+	RaidDisks  int
+	Degraded   int
+	SyncAction string // idle, resync, recover, check, repair, reshape
+	SyncDone   uint64 // sectors
+	SyncTotal  uint64
+	// Current resync speed in KiB/s
+	SyncSpeed uint64
+	Members   []RAIDMember
+}
+
+// Syncing reports whether a resync, rebuild, check or reshape is running
+func (a RAIDArray) Syncing() bool {
+	return a.SyncAction != "" && a.SyncAction != "idle" && a.SyncTotal > 0
+}
+
+func (a RAIDArray) Progress() float64 {
+	if a.SyncTotal == 0 {
+		return 0
+	}
+	return float64(a.SyncDone) / float64(a.SyncTotal)
+}
+
+// ETA extrapolates from the kernel's current sync speed; zero when unknown
+func (a RAIDArray) ETA() time.Duration {
+	if a.SyncSpeed == 0 || a.SyncDone >= a.SyncTotal {
+		return 0
+	}
+	remainingKiB := (a.SyncTotal - a.SyncDone) / 2
+	return time.Duration(remainingKiB/a.SyncSpeed) * time.Second
+}
+
+type mdstatEntry struct {
+	Level  string
+	Status string
+}
+
+// "md0 : active raid1 sdb1[1] sda1[0]"
+var mdstatArrayRe = regexp.MustCompile(`^(md\S*) : (\S+)(?: \(\S+\))? (\S+)`)
+
+// "1953382464 blocks super 1.2 [2/2] [UU]"
+var mdstatStatusRe = regexp.MustCompile(`\[\d+/\d+\] \[([U_]+)\]`)
+
+// parseMDStat pulls the level and member status map for each array out of /proc/mdstat
+func parseMDStat(data string) map[string]mdstatEntry {
+	arrays := map[string]mdstatEntry{}
+	current := ""
+	for _, line := range strings.Split(data, "\n") {
+		if m := mdstatArrayRe.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			entry := mdstatEntry{}
+			// Inactive arrays have no personality, the third word is already a member
+			if strings.HasPrefix(m[3], "raid") || m[3] == "linear" || m[3] == "multipath" {
+				entry.Level = m[3]
+			}
+			arrays[current] = entry
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			current = ""
+			continue
+		}
+		if m := mdstatStatusRe.FindStringSubmatch(line); m != nil {
+			entry := arrays[current]
+			entry.Status = "[" + m[1] + "]"
+			arrays[current] = entry
+		}
+	}
+	return arrays
+}
+
+func GetRAIDArrays() []RAIDArray {
+	data, err := os.ReadFile("/proc/mdstat")
+	if err != nil {
+		return nil
+	}
+	mdstat := parseMDStat(string(data))
+	var arrays []RAIDArray
+	for name, entry := range mdstat {
+		base := "/sys/block/" + name + "/md/"
+		a := RAIDArray{
+			Name:       name,
+			Level:      readSysString(base + "level"),
+			State:      readSysString(base + "array_state"),
+			Status:     entry.Status,
+			SyncAction: readSysString(base + "sync_action"),
+		}
+		if a.Level == "" {
+			a.Level = entry.Level
+		}
+		a.RaidDisks, _ = strconv.Atoi(readSysString(base + "raid_disks"))
+		a.Degraded, _ = strconv.Atoi(readSysString(base + "degraded"))
+		a.SyncSpeed, _ = strconv.ParseUint(readSysString(base+"sync_speed"), 10, 64)
+		// sync_completed is "done / total" in sectors, or "none"
+		if done, total, ok := strings.Cut(readSysString(base+"sync_completed"), "/"); ok {
+			a.SyncDone, _ = strconv.ParseUint(strings.TrimSpace(done), 10, 64)
+			a.SyncTotal, _ = strconv.ParseUint(strings.TrimSpace(total), 10, 64)
+		}
+		members, _ := filepath.Glob(base + "dev-*")
+		for _, m := range members {
+			member := RAIDMember{
+				Name:  strings.TrimPrefix(filepath.Base(m), "dev-"),
+				State: readSysString(m + "/state"),
+			}
+			a.Members = append(a.Members, member)
+		}
+		sort.Slice(a.Members, func(i, j int) bool { return a.Members[i].Name < a.Members[j].Name })
+		arrays = append(arrays, a)
+	}
+	sort.Slice(arrays, func(i, j int) bool { return arrays[i].Name < arrays[j].Name })
+	return arrays
+}
@@ -2,119 +2,338 @@ package main
 
 import (
 	"encoding/binary"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
 type RAMBank struct {
-	Locator      string
-	BankLocator  string
-	SizeMB       uint32
-	SpeedMHz     uint16
-	MemoryType   string
-	Manufacturer string
-}
-
-// Map some known manufacturer IDs to friendly names (add more as needed)
-var manufacturerMap = map[string]string{
-	"029E": "Corsair",
-	"80CE": "Kingston",
-	"04CD": "Samsung",
-	"049F": "Micron",
-	"02C0": "Crucial",
-	"0417": "ADATA",
+	Populated           bool // false for an empty slot
+	Locator             string
+	BankLocator         string
+	SizeMB              uint32
+	SpeedMHz            uint32 // rated speed in MT/s
+	ConfiguredSpeedMHz  uint32 // speed the memory controller actually runs, 0 if unknown
+	MemoryType          string
+	TypeDetail          []string
+	FormFactor          string
+	Rank                int // 0 if unknown
+	MinVoltageMV        uint16
+	MaxVoltageMV        uint16
+	ConfiguredVoltageMV uint16
+	Manufacturer        string
+	ModuleManufacturer  uint16 // JEP106 bank/ID pair, 0 if not reported
+	PartNumber          string
+	Serial              string
+	AssetTag            string
 }
 
+// Memory Type, SMBIOS DSP0134 7.18.2; 0x15-0x17 are reserved
 var memoryTypeMap = map[byte]string{
 	0x01: "Other",
-	0x02: "DRAM",
-	0x03: "Synchronous DRAM",
-	0x04: "Cache DRAM",
-	0x05: "EDO",
-	0x06: "EDRAM",
-	0x07: "VRAM",
-	0x08: "SRAM",
-	0x09: "RAM",
-	0x0A: "ROM",
-	0x0B: "Flash",
-	0x0C: "EEPROM",
-	0x0D: "FEPROM",
-	0x0E: "EPROM",
-	0x0F: "CDRAM",
-	0x10: "3DRAM",
-	0x11: "SDRAM",
-	0x12: "SGRAM",
-	0x13: "RDRAM",
-	0x14: "DDR",
-	0x15: "DDR2",
+	0x02: "Unknown",
+	0x03: "DRAM",
+	0x04: "EDRAM",
+	0x05: "VRAM",
+	0x06: "SRAM",
+	0x07: "RAM",
+	0x08: "ROM",
+	0x09: "Flash",
+	0x0A: "EEPROM",
+	0x0B: "FEPROM",
+	0x0C: "EPROM",
+	0x0D: "CDRAM",
+	0x0E: "3DRAM",
+	0x0F: "SDRAM",
+	0x10: "SGRAM",
+	0x11: "RDRAM",
+	0x12: "DDR",
+	0x13: "DDR2",
+	0x14: "DDR2 FB-DIMM",
 	0x18: "DDR3",
+	0x19: "FBD2",
 	0x1A: "DDR4",
-	0x1C: "DDR5",
+	0x1B: "LPDDR",
+	0x1C: "LPDDR2",
+	0x1D: "LPDDR3",
+	0x1E: "LPDDR4",
+	0x1F: "Logical non-volatile device",
+	0x20: "HBM",
+	0x21: "HBM2",
+	0x22: "DDR5",
+	0x23: "LPDDR5",
+	0x24: "HBM3",
+}
+
+var formFactorMap = map[byte]string{
+	0x01: "Other",
+	0x03: "SIMM",
+	0x04: "SIP",
+	0x05: "Chip",
+	0x06: "DIP",
+	0x07: "ZIP",
+	0x08: "Proprietary Card",
+	0x09: "DIMM",
+	0x0A: "TSOP",
+	0x0B: "Row of chips",
+	0x0C: "RIMM",
+	0x0D: "SODIMM",
+	0x0E: "SRIMM",
+	0x0F: "FB-DIMM",
+	0x10: "Die",
+}
+
+// Bit positions of the Type Detail word
+var typeDetailBits = []struct {
+	bit  uint
+	name string
+}{
+	{3, "Fast-paged"},
+	{4, "Static column"},
+	{5, "Pseudo-static"},
+	{6, "RAMBUS"},
+	{7, "Synchronous"},
+	{8, "CMOS"},
+	{9, "EDO"},
+	{10, "Window DRAM"},
+	{11, "Cache DRAM"},
+	{12, "Non-volatile"},
+	{13, "Registered"},
+	{14, "Unbuffered"},
+	{15, "LRDIMM"},
 }
 
+// GetRAMBanks returns every memory slot in SMBIOS order, empty ones included
 func GetRAMBanks() ([]RAMBank, error) {
-	rawFiles, err := filepath.Glob("/sys/firmware/dmi/entries/17-*/raw")
-	if err != nil {
-		return nil, err
+	var banks []RAMBank
+	for _, raw := range readDMIEntries(17) {
+		if bank, ok := ParseMemoryDevice(raw); ok {
+			banks = append(banks, bank)
+		}
 	}
+	return banks, nil
+}
 
-	var banks []RAMBank
+// MemoryArray sums the SMBIOS type 16 arrays used as system memory
+type MemoryArray struct {
+	MaxCapacityBytes uint64
+	Slots            int
+}
 
-	for _, file := range rawFiles {
-		raw, err := os.ReadFile(file)
-		if err != nil || len(raw) < 0x1C {
+func GetMemoryArray() MemoryArray {
+	var total MemoryArray
+	for _, raw := range readDMIEntries(16) {
+		a, ok := ParseMemoryArray(raw)
+		if !ok {
 			continue
 		}
+		total.MaxCapacityBytes += a.MaxCapacityBytes
+		total.Slots += a.Slots
+	}
+	return total
+}
 
-		structLen := int(raw[1])
-		if len(raw) < structLen {
-			continue
+// ParseMemoryArray decodes an SMBIOS type 16 (Physical Memory Array)
+// structure. Arrays not used as system memory (cache, flash) are rejected.
+func ParseMemoryArray(raw []byte) (MemoryArray, bool) {
+	if len(raw) < 0x0F || raw[0] != 16 {
+		return MemoryArray{}, false
+	}
+	structLen := int(raw[1])
+	if structLen < 0x0F || len(raw) < structLen {
+		return MemoryArray{}, false
+	}
+	const useSystemMemory = 0x03
+	if raw[0x05] != useSystemMemory {
+		return MemoryArray{}, false
+	}
+	a := MemoryArray{Slots: int(binary.LittleEndian.Uint16(raw[0x0D:]))}
+	// Maximum Capacity is in KB; 0x80000000 defers to the 2.7 extended field in bytes
+	maxKB := binary.LittleEndian.Uint32(raw[0x07:])
+	if maxKB == 0x80000000 {
+		if structLen >= 0x17 {
+			a.MaxCapacityBytes = binary.LittleEndian.Uint64(raw[0x0F:])
 		}
+	} else {
+		a.MaxCapacityBytes = uint64(maxKB) * 1024
+	}
+	return a, true
+}
 
-		strSection := raw[structLen:]
-		strs := parseDMIStrings(strSection)
+// UpgradeSummary answers "can I add RAM?", e.g. "2 of 4 slots free, max 128.00 GiB"
+func UpgradeSummary(banks []RAMBank, array MemoryArray) string {
+	slots := array.Slots
+	if len(banks) > slots {
+		slots = len(banks)
+	}
+	if slots == 0 {
+		return ""
+	}
+	free := slots
+	for _, b := range banks {
+		if b.Populated {
+			free--
+		}
+	}
+	summary := fmt.Sprintf("%d of %d slots free", free, slots)
+	if array.MaxCapacityBytes > 0 {
+		summary += ", max " + formatBytes(array.MaxCapacityBytes)
+	}
+	return summary
+}
 
-		getIndex := func(offset int) int {
-			if offset < structLen && offset < len(raw) {
-				return int(raw[offset])
-			}
-			return 0
+// readDMIEntries returns the raw structures of one SMBIOS type, ordered by
+// instance number (the glob alone would put 17-10 before 17-2)
+func readDMIEntries(dmiType int) [][]byte {
+	dirs, _ := filepath.Glob(fmt.Sprintf("/sys/firmware/dmi/entries/%d-*", dmiType))
+	instance := func(dir string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), fmt.Sprintf("%d-", dmiType)))
+		return n
+	}
+	sort.Slice(dirs, func(i, j int) bool { return instance(dirs[i]) < instance(dirs[j]) })
+	var entries [][]byte
+	for _, dir := range dirs {
+		if raw, err := os.ReadFile(dir + "/raw"); err == nil {
+			entries = append(entries, raw)
 		}
+	}
+	return entries
+}
 
-		size := binary.LittleEndian.Uint16(raw[0x0C:0x0E])
-		if size == 0 || size == 0xFFFF {
-			continue
+// ParseMemoryDevice decodes an SMBIOS type 17 (Memory Device) structure,
+// formatted area followed by its string set, up to SMBIOS 3.3. Fields the
+// structure is too short to carry are left zero.
+func ParseMemoryDevice(raw []byte) (RAMBank, bool) {
+	if len(raw) < 0x15 || raw[0] != 17 {
+		return RAMBank{}, false
+	}
+	structLen := int(raw[1])
+	if structLen < 0x15 || len(raw) < structLen {
+		return RAMBank{}, false
+	}
+	strs := parseDMIStrings(raw[structLen:])
+
+	has := func(offset, size int) bool {
+		return offset+size <= structLen
+	}
+	word := func(offset int) uint16 {
+		if !has(offset, 2) {
+			return 0
+		}
+		return binary.LittleEndian.Uint16(raw[offset:])
+	}
+	dword := func(offset int) uint32 {
+		if !has(offset, 4) {
+			return 0
+		}
+		return binary.LittleEndian.Uint32(raw[offset:])
+	}
+	str := func(offset int) string {
+		if !has(offset, 1) {
+			return ""
 		}
+		return strings.TrimSpace(safeString(strs, int(raw[offset])))
+	}
 
-		speed := binary.LittleEndian.Uint16(raw[0x15:0x17])
-		memType := memoryTypeMap[raw[0x12]]
+	bank := RAMBank{
+		Locator:     str(0x10),
+		BankLocator: str(0x11),
+		MemoryType:  memoryTypeMap[raw[0x12]],
+		FormFactor:  formFactorMap[raw[0x0E]],
+	}
 
-		rawManufacturer := strings.TrimSpace(safeString(strs, getIndex(0x17)))
-		prettyManufacturer := mapManufacturer(rawManufacturer)
+	// 0 means an empty slot, 0xFFFF unknown, 0x7FFF "see Extended Size";
+	// bit 15 switches the unit from MB to KB
+	size := word(0x0C)
+	bank.Populated = size != 0
+	switch {
+	case size == 0xFFFF:
+	case size == 0x7FFF:
+		bank.SizeMB = dword(0x1C) & 0x7FFFFFFF
+	case size&0x8000 != 0:
+		bank.SizeMB = uint32(size&0x7FFF) / 1024
+	default:
+		bank.SizeMB = uint32(size)
+	}
 
-		bank := RAMBank{
-			SizeMB:       uint32(size),
-			SpeedMHz:     speed,
-			MemoryType:   memType,
-			Locator:      strings.TrimSpace(safeString(strs, getIndex(0x10))),
-			BankLocator:  strings.TrimSpace(safeString(strs, getIndex(0x11))),
-			Manufacturer: prettyManufacturer,
+	detail := word(0x13)
+	for _, d := range typeDetailBits {
+		if detail&(1<<d.bit) != 0 {
+			bank.TypeDetail = append(bank.TypeDetail, d.name)
 		}
+	}
+
+	// 0xFFFF defers to the 32-bit extended speed fields added in 3.3
+	bank.SpeedMHz = uint32(word(0x15))
+	if bank.SpeedMHz == 0xFFFF {
+		bank.SpeedMHz = dword(0x54)
+	}
+	bank.ConfiguredSpeedMHz = uint32(word(0x20))
+	if bank.ConfiguredSpeedMHz == 0xFFFF {
+		bank.ConfiguredSpeedMHz = dword(0x58)
+	}
 
-		banks = append(banks, bank)
+	// SMBIOS 3.2: Firmware Version string at 0x2B, Module Manufacturer ID at 0x2C
+	bank.ModuleManufacturer = word(0x2C)
+	bank.Manufacturer = mapManufacturer(str(0x17), bank.ModuleManufacturer)
+	bank.Serial = str(0x18)
+	bank.AssetTag = str(0x19)
+	bank.PartNumber = str(0x1A)
+	if has(0x1B, 1) {
+		bank.Rank = int(raw[0x1B] & 0x0F)
 	}
+	bank.MinVoltageMV = word(0x22)
+	bank.MaxVoltageMV = word(0x24)
+	bank.ConfiguredVoltageMV = word(0x26)
+	return bank, true
+}
 
-	return banks, nil
+// Summary condenses a bank into e.g. "DDR5-6000 (running 4800) 2R 1.35V F5-6000J3038F16G"
+func (b RAMBank) Summary() string {
+	var parts []string
+	speed := b.MemoryType
+	if b.SpeedMHz > 0 {
+		speed = fmt.Sprintf("%s-%d", b.MemoryType, b.SpeedMHz)
+	}
+	if b.ConfiguredSpeedMHz > 0 && b.ConfiguredSpeedMHz != b.SpeedMHz {
+		speed += fmt.Sprintf(" (running %d)", b.ConfiguredSpeedMHz)
+	}
+	if speed != "" {
+		parts = append(parts, speed)
+	}
+	if b.Rank > 0 {
+		parts = append(parts, fmt.Sprintf("%dR", b.Rank))
+	}
+	voltage := b.ConfiguredVoltageMV
+	if voltage == 0 {
+		voltage = b.MinVoltageMV
+	}
+	if voltage > 0 {
+		parts = append(parts, strconv.FormatFloat(float64(voltage)/1000, 'f', -1, 64)+"V")
+	}
+	if b.PartNumber != "" {
+		parts = append(parts, b.PartNumber)
+	}
+	return strings.Join(parts, " ")
 }
 
-func mapManufacturer(code string) string {
-	if name, ok := manufacturerMap[code]; ok {
+// mapManufacturer turns the type 17 manufacturer string into a vendor name.
+// Many BIOSes store the raw JEP106 code there ("80CE") or nothing useful at
+// all, in which case the SMBIOS 3.2 module manufacturer ID is tried.
+func mapManufacturer(code string, moduleID uint16) string {
+	if name, ok := decodeJEP106Hex(code); ok {
 		return name
 	}
-	if code != "" {
+	if code != "" && !strings.EqualFold(code, "unknown") && !strings.HasPrefix(code, "0000") {
 		return code
 	}
+	// Low byte is the continuation count, high byte the ID
+	if name := JEP106Name(byte(moduleID), byte(moduleID>>8)); moduleID != 0 && name != "" {
+		return name
+	}
 	return "Unknown"
 }
 
@@ -128,14 +347,16 @@ func safeString(strings []string, index int) string {
 func parseDMIStrings(data []byte) []string {
 	var out []string
 	start := 0
-	for i := 0; i < len(data)-1; i++ {
-		if data[i] == 0 && data[i+1] == 0 {
-			break
+	for i := 0; i < len(data); i++ {
+		if data[i] != 0 {
+			continue
 		}
-		if data[i] == 0 {
-			out = append(out, string(data[start:i]))
-			start = i + 1
+		// A zero right at the start of a string ends the set
+		if i == start {
+			break
 		}
+		out = append(out, string(data[start:i]))
+		start = i + 1
 	}
 	return out
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// memoryArray builds a type 16 structure of the given formatted length, followed
+// by the empty string set, the way it appears in /sys/firmware/dmi/entries
+func memoryArray(length int, use byte, maxKB uint32, slots uint16, extended uint64) []byte {
+	raw := make([]byte, length+2)
+	raw[0] = 16
+	raw[1] = byte(length)
+	raw[0x04] = 0x03 // system board
+	raw[0x05] = use
+	raw[0x06] = 0x03 // no error correction
+	binary.LittleEndian.PutUint32(raw[0x07:], maxKB)
+	binary.LittleEndian.PutUint16(raw[0x0B:], 0xFFFE)
+	binary.LittleEndian.PutUint16(raw[0x0D:], slots)
+	if length >= 0x17 {
+		binary.LittleEndian.PutUint64(raw[0x0F:], extended)
+	}
+	return raw
+}
+
+func TestParseMemoryArray(t *testing.T) {
+	const gib = 1 << 30
+	tests := []struct {
+		name string
+		raw  []byte
+		want MemoryArray
+		ok   bool
+	}{
+		{
+			name: "SMBIOS 2.1 length",
+			raw:  memoryArray(0x0F, 0x03, 32*1024*1024, 2, 0),
+			want: MemoryArray{MaxCapacityBytes: 32 * gib, Slots: 2},
+			ok:   true,
+		},
+		{
+			name: "SMBIOS 2.7 length, KB field in use",
+			raw:  memoryArray(0x17, 0x03, 128*1024*1024, 4, 0),
+			want: MemoryArray{MaxCapacityBytes: 128 * gib, Slots: 4},
+			ok:   true,
+		},
+		{
+			name: "SMBIOS 2.7 length, extended field",
+			raw:  memoryArray(0x17, 0x03, 0x80000000, 32, 8192*gib),
+			want: MemoryArray{MaxCapacityBytes: 8192 * gib, Slots: 32},
+			ok:   true,
+		},
+		{
+			// The extended field doesn't exist before 2.7, so the limit is unknown
+			name: "extended marker on a 2.1 structure",
+			raw:  memoryArray(0x0F, 0x03, 0x80000000, 2, 0),
+			want: MemoryArray{Slots: 2},
+			ok:   true,
+		},
+		{
+			name: "cache memory array",
+			raw:  memoryArray(0x17, 0x05, 2048, 1, 0),
+		},
+		{
+			name: "flash memory array",
+			raw:  memoryArray(0x0F, 0x04, 16*1024, 1, 0),
+		},
+		{
+			name: "wrong structure type",
+			raw:  append([]byte{17}, memoryArray(0x0F, 0x03, 1024, 1, 0)[1:]...),
+		},
+		{
+			name: "truncated",
+			raw:  memoryArray(0x17, 0x03, 0x80000000, 4, 64*gib)[:0x10],
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseMemoryArray(tt.raw)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("ParseMemoryArray = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Below this share of installed DIMM capacity MemTotal is worth pointing out;
+// firmware and iGPU reservations normally take only a few percent
+const memTotalShortfall = 0.85
+
+// memoryRows shows live usage from /proc/meminfo. installedMB is the DIMM
+// total from SMBIOS, 0 when unknown.
+func memoryRows(m MemInfo, installedMB uint64) []fyne.CanvasObject {
+	if m.Total == 0 {
+		return nil
+	}
+	used := float64(m.Used()) / float64(m.Total)
+	rows := []fyne.CanvasObject{
+		container.NewHBox(
+			widget.NewLabelWithStyle("Used:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.NewCenter(makeBar(used, fullnessColor(used))),
+			widget.NewLabel(fmt.Sprintf("%s of %s (%.0f%%), %s available",
+				formatBytes(m.Used()), formatBytes(m.Total), used*100, formatBytes(m.Available))),
+		),
+		widget.NewLabel(fmt.Sprintf("Cached %s  Buffers %s  Dirty %s  Shmem %s  Slab %s",
+			formatBytes(m.Cached), formatBytes(m.Buffers), formatBytes(m.Dirty), formatBytes(m.Shmem), formatBytes(m.Slab))),
+	}
+	if m.SwapTotal > 0 {
+		swap := float64(m.SwapUsed()) / float64(m.SwapTotal)
+		rows = append(rows, container.NewHBox(
+			widget.NewLabelWithStyle("Swap:", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+			container.NewCenter(makeBar(swap, fullnessColor(swap))),
+			widget.NewLabel(fmt.Sprintf("%s of %s", formatBytes(m.SwapUsed()), formatBytes(m.SwapTotal))),
+		))
+	} else {
+		rows = append(rows, widget.NewLabel("Swap: none"))
+	}
+	if m.HugePagesTotal > 0 {
+		rows = append(rows, widget.NewLabel(fmt.Sprintf("HugePages: %d of %d free (%s each)",
+			m.HugePagesFree, m.HugePagesTotal, formatBytes(m.HugePageSize))))
+	}
+	installed := installedMB * 1024 * 1024
+	if installed > 0 && float64(m.Total) < float64(installed)*memTotalShortfall {
+		note := widget.NewLabel(fmt.Sprintf("Kernel sees %s of %s installed: memory reserved by firmware/iGPU or a DIMM not detected",
+			formatBytes(m.Total), formatBytes(installed)))
+		note.Wrapping = fyne.TextWrapWord
+		note.Importance = widget.WarningImportance
+		rows = append(rows, note)
+	}
+	return rows
+}
+
+// spdRows lists the module and DRAM chip vendors read from SPD
+func spdRows(modules []SPDModule) []fyne.CanvasObject {
+	var rows []fyne.CanvasObject
+	for _, m := range modules {
+		line := fmt.Sprintf("SPD %s: %s", m.Device, m.MemoryType)
+		if m.ModuleManufacturer != "" {
+			line += ", module " + m.ModuleManufacturer
+		}
+		if m.DRAMManufacturer != "" {
+			line += ", DRAM " + m.DRAMManufacturer
+		}
+		if m.PartNumber != "" {
+			line += ", " + m.PartNumber
+		}
+		rows = append(rows, widget.NewLabel(line))
+	}
+	return rows
+}
@@ -14,33 +14,45 @@ import (
 
 func MakeSection(title string, sensors map[string]float64, fans map[string]int, tempThreshold float64, showNoData bool, fanLabelMap map[string]string) fyne.CanvasObject {
 	if title == "CPU Temp" {
-		// Dynamically show all detected core temps, sorted, each on its own line, with color
-		coreKeys := []int{}
-		coreMap := map[int]float64{}
+		// Dynamically show all detected core temps, sorted by socket then core,
+		// each on its own line, with color
+		type socketCore struct{ socket, core int }
+		coreKeys := []socketCore{}
+		coreMap := map[socketCore]float64{}
+		multiSocket := false
 		for k, v := range sensors {
-			lk := strings.ToLower(k)
+			socket, label, prefixed := splitSocketPrefix(k)
+			lk := strings.ToLower(label)
 			// Match "core N" or "coreN"
 			var coreNum int
-			if _, err := fmt.Sscanf(lk, "core %d", &coreNum); err == nil {
-				coreKeys = append(coreKeys, coreNum)
-				coreMap[coreNum] = v
-			} else if _, err := fmt.Sscanf(lk, "core%d", &coreNum); err == nil {
-				coreKeys = append(coreKeys, coreNum)
-				coreMap[coreNum] = v
+			if _, err := fmt.Sscanf(lk, "core %d", &coreNum); err != nil {
+				if _, err := fmt.Sscanf(lk, "core%d", &coreNum); err != nil {
+					continue
+				}
 			}
-		}
-		// Remove duplicates
-		unique := map[int]struct{}{}
-		filteredKeys := []int{}
-		for _, n := range coreKeys {
-			if _, ok := unique[n]; !ok {
-				unique[n] = struct{}{}
-				filteredKeys = append(filteredKeys, n)
+			key := socketCore{socket, coreNum}
+			if _, seen := coreMap[key]; !seen {
+				coreKeys = append(coreKeys, key)
 			}
+			coreMap[key] = v
+			multiSocket = multiSocket || prefixed
 		}
-		sort.Ints(filteredKeys)
+		sort.Slice(coreKeys, func(i, j int) bool {
+			if coreKeys[i].socket != coreKeys[j].socket {
+				return coreKeys[i].socket < coreKeys[j].socket
+			}
+			return coreKeys[i].core < coreKeys[j].core
+		})
 		var coreRows []fyne.CanvasObject
-		for _, n := range filteredKeys {
+		// Package level temps first: Intel "Package id N", AMD Tctl/Tdie and per-CCD Tccd
+		for _, t := range CPUPackageTemps(sensors) {
+			row := container.NewHBox(
+				widget.NewLabelWithStyle(t.Label+":", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+				tempText(t.Temp, tempThreshold),
+			)
+			coreRows = append(coreRows, row)
+		}
+		for _, n := range coreKeys {
 			temp := coreMap[n]
 			col := colorTemp(temp, tempThreshold)
 			var tempColor color.Color
@@ -49,7 +61,11 @@ func MakeSection(title string, sensors map[string]float64, fans map[string]int,
 			} else {
 				tempColor = color.RGBA{0, 180, 0, 255}
 			}
-			label := widget.NewLabelWithStyle(fmt.Sprintf("Core %d:", n), fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
+			name := fmt.Sprintf("Core %d:", n.core)
+			if multiSocket {
+				name = fmt.Sprintf("Socket %d Core %d:", n.socket, n.core)
+			}
+			label := widget.NewLabelWithStyle(name, fyne.TextAlignLeading, fyne.TextStyle{Bold: true})
 			value := canvas.NewText(fmt.Sprintf("%.1f°C", temp), tempColor)
 			value.TextStyle = fyne.TextStyle{Bold: true}
 			value.Alignment = fyne.TextAlignLeading
@@ -214,6 +230,62 @@ func colorTemp(temp float64, threshold float64) string {
 	return "green"
 }
 
+// barLayout stretches the first object across the full size and the second
+// across fraction of the width, giving a simple horizontal meter
+type barLayout struct {
+	fraction float64
+}
+
+func (b *barLayout) Layout(objects []fyne.CanvasObject, size fyne.Size) {
+	objects[0].Move(fyne.NewPos(0, 0))
+	objects[0].Resize(size)
+	objects[1].Move(fyne.NewPos(0, 0))
+	objects[1].Resize(fyne.NewSize(size.Width*float32(b.fraction), size.Height))
+}
+
+func (b *barLayout) MinSize(objects []fyne.CanvasObject) fyne.Size {
+	return fyne.NewSize(120, 12)
+}
+
+// makeBar draws a meter filled to fraction (0..1)
+func makeBar(fraction float64, fill color.Color) fyne.CanvasObject {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	bg := canvas.NewRectangle(color.RGBA{30, 30, 40, 255})
+	fg := canvas.NewRectangle(fill)
+	return container.New(&barLayout{fraction: fraction}, bg, fg)
+}
+
+// fullnessColor goes green → amber → red as a meter fills up
+func fullnessColor(fraction float64) color.Color {
+	switch {
+	case fraction >= 0.9:
+		return color.RGBA{220, 0, 0, 255}
+	case fraction >= 0.75:
+		return color.RGBA{230, 160, 0, 255}
+	default:
+		return color.RGBA{0, 180, 0, 255}
+	}
+}
+
+// tempText renders a bold temperature, red once it reaches threshold
+func tempText(temp float64, threshold float64) *canvas.Text {
+	var tempColor color.Color
+	if colorTemp(temp, threshold) == "red" {
+		tempColor = color.RGBA{220, 0, 0, 255}
+	} else {
+		tempColor = color.RGBA{0, 180, 0, 255}
+	}
+	value := canvas.NewText(fmt.Sprintf("%.1f°C", temp), tempColor)
+	value.TextStyle = fyne.TextStyle{Bold: true}
+	value.Alignment = fyne.TextAlignLeading
+	return value
+}
+
 func getFanLabel(key string, fanLabelMap map[string]string) string {
 	if val, ok := fanLabelMap[key]; ok && val != "" {
 		return val
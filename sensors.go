@@ -3,8 +3,11 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 func readSensors() SensorData {
@@ -14,15 +17,28 @@ func readSensors() SensorData {
 	// Read from /sys/class/hwmon/hwmon*/
 	hwmonBase := "/sys/class/hwmon/"
 	if hwmons, err := os.ReadDir(hwmonBase); err == nil {
+		// Multi-socket machines have one coretemp/k10temp per package, all with
+		// the same labels; count them so their readings can be told apart
+		names := map[string]string{}
+		instances := map[string]int{}
 		for _, hw := range hwmons {
-			hwPath := hwmonBase + hw.Name() + "/"
-			// Try to get sensor name
 			name := hw.Name()
-			if nBytes, err := os.ReadFile(hwPath + "name"); err == nil {
+			if nBytes, err := os.ReadFile(hwmonBase + hw.Name() + "/name"); err == nil {
 				name = strings.TrimSpace(string(nBytes))
 			}
-			// Find temp sensors and use temp*_label if available
-			for i := 1; i <= 10; i++ {
+			names[hw.Name()] = name
+			instances[name]++
+		}
+		for _, hw := range hwmons {
+			hwPath := hwmonBase + hw.Name() + "/"
+			name := names[hw.Name()]
+			prefix := ""
+			if isCPUHwmon(name) && instances[name] > 1 {
+				prefix = cpuHwmonPrefix(hwPath, name, instances[name])
+			}
+			// Find temp sensors and use temp*_label if available. k10temp on
+			// 12-CCD EPYC parts goes up to temp14 (Tccd12).
+			for i := 1; i <= 16; i++ {
 				tPath := fmt.Sprintf("%stemp%d_input", hwPath, i)
 				labelPath := fmt.Sprintf("%stemp%d_label", hwPath, i)
 				var label string
@@ -35,7 +51,7 @@ func readSensors() SensorData {
 					tVal, err := strconv.ParseFloat(strings.TrimSpace(string(tBytes)), 64)
 					if err == nil {
 						// hwmon reports in millidegrees C
-						temps[label] = tVal / 1000.0
+						temps[prefix+label] = tVal / 1000.0
 					}
 				}
 			}
@@ -88,9 +104,10 @@ func categorizeSensors(sensor SensorData) (moboTemps, cpuTemps, gpuTemps, hdTemp
 	gpuFans = map[string]int{}
 	moboFans = map[string]int{}
 	for k, v := range sensor.Temperatures {
-		lk := strings.ToLower(k)
-		// Only include 'Core N' temps in cpuTemps, ignore 'Package id' and other package temps
-		if strings.HasPrefix(lk, "core ") {
+		_, label, _ := splitSocketPrefix(k)
+		lk := strings.ToLower(label)
+		// Intel coretemp reports "Core N" and "Package id N", AMD k10temp Tctl, Tdie and TccdN
+		if strings.HasPrefix(lk, "core ") || isCPUPackageSensor(lk) {
 			cpuTemps[k] = v
 			continue
 		}
@@ -116,3 +133,248 @@ func categorizeSensors(sensor SensorData) (moboTemps, cpuTemps, gpuTemps, hdTemp
 	}
 	return
 }
+
+type LabeledTemp struct {
+	Label string
+	Temp  float64
+}
+
+func isCPUPackageSensor(lk string) bool {
+	return strings.HasPrefix(lk, "package id ") || lk == "tctl" || lk == "tdie" || strings.HasPrefix(lk, "tccd")
+}
+
+// CPUPackageTemps picks out package and per-CCD temperatures in display
+// order: per socket, Intel package, then AMD Tctl/Tdie, then CCDs by number
+func CPUPackageTemps(sensors map[string]float64) []LabeledTemp {
+	type ordered struct {
+		socket, rank, n int
+		t               LabeledTemp
+	}
+	var temps []ordered
+	for k, v := range sensors {
+		socket, label, prefixed := splitSocketPrefix(k)
+		lk := strings.ToLower(label)
+		prefix := ""
+		if prefixed {
+			prefix = k[:len(k)-len(label)]
+		}
+		var n int
+		switch {
+		case strings.HasPrefix(lk, "package id "):
+			fmt.Sscanf(lk, "package id %d", &n)
+			name := fmt.Sprintf("Package %d", n)
+			if prefixed {
+				name = prefix + "Package"
+			}
+			temps = append(temps, ordered{socket, 0, n, LabeledTemp{name, v}})
+		case lk == "tctl":
+			// Tctl is the control value and can carry a fan-curve offset on some parts
+			temps = append(temps, ordered{socket, 1, 0, LabeledTemp{prefix + "Package (Tctl)", v}})
+		case lk == "tdie":
+			temps = append(temps, ordered{socket, 2, 0, LabeledTemp{prefix + "Die (Tdie)", v}})
+		case strings.HasPrefix(lk, "tccd"):
+			fmt.Sscanf(lk, "tccd%d", &n)
+			temps = append(temps, ordered{socket, 3, n, LabeledTemp{fmt.Sprintf("%sCCD %d", prefix, n), v}})
+		}
+	}
+	sort.Slice(temps, func(i, j int) bool {
+		a, b := temps[i], temps[j]
+		if a.socket != b.socket {
+			return a.socket < b.socket
+		}
+		if a.rank != b.rank {
+			return a.rank < b.rank
+		}
+		return a.n < b.n
+	})
+	result := make([]LabeledTemp, 0, len(temps))
+	for _, t := range temps {
+		result = append(result, t.t)
+	}
+	return result
+}
+
+func isCPUHwmon(name string) bool {
+	return name == "coretemp" || name == "k10temp" || name == "zenpower"
+}
+
+// cpuHwmonPrefix names the package a CPU hwmon belongs to, e.g. "Socket 1 ".
+// coretemp registers coretemp.N per package; k10temp binds to the data fabric
+// function at PCI device 18h+node. Naples has one node per die rather than per
+// socket, so when the instance count doesn't match the sockets they are nodes.
+func cpuHwmonPrefix(hwPath, name string, instances int) string {
+	dev, err := filepath.EvalSymlinks(hwPath + "device")
+	if err != nil {
+		return ""
+	}
+	base := filepath.Base(dev)
+	var n int
+	if name == "coretemp" {
+		if _, err := fmt.Sscanf(base, "coretemp.%d", &n); err != nil {
+			return ""
+		}
+		return fmt.Sprintf("Socket %d ", n)
+	}
+	// PCI address like 0000:00:19.3
+	var domain, bus, slot, fn int
+	if _, err := fmt.Sscanf(base, "%x:%x:%x.%x", &domain, &bus, &slot, &fn); err != nil || slot < 0x18 {
+		return ""
+	}
+	if instances != socketCount() {
+		return fmt.Sprintf("Node %d ", slot-0x18)
+	}
+	return fmt.Sprintf("Socket %d ", slot-0x18)
+}
+
+// splitSocketPrefix undoes cpuHwmonPrefix: "Socket 1 Tctl" → 1, "Tctl", true
+func splitSocketPrefix(key string) (int, string, bool) {
+	for _, kind := range []string{"Socket ", "Node "} {
+		rest, ok := strings.CutPrefix(key, kind)
+		if !ok {
+			continue
+		}
+		num, label, ok := strings.Cut(rest, " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(num); err == nil {
+			return n, label, true
+		}
+	}
+	return 0, key, false
+}
+
+var socketCountOnce sync.Once
+var socketCountValue int
+
+// socketCount counts distinct physical packages; it can't change at runtime
+func socketCount() int {
+	socketCountOnce.Do(func() {
+		ids := map[string]bool{}
+		paths, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/topology/physical_package_id")
+		for _, p := range paths {
+			ids[readSysString(p)] = true
+		}
+		socketCountValue = len(ids)
+	})
+	return socketCountValue
+}
+
+// CoreKey identifies a physical core the way coretemp and sysfs topology do
+type CoreKey struct {
+	Package int
+	Core    int
+}
+
+// GetCoreTemps reads Intel coretemp per-core temperatures keyed by package and
+// core_id. Each package has its own coretemp hwmon with its own "Core N"
+// labels, which is why readSensors' label keyed map can't be used.
+func GetCoreTemps() map[CoreKey]float64 {
+	temps := map[CoreKey]float64{}
+	hwmons, _ := filepath.Glob("/sys/class/hwmon/hwmon*")
+	for _, hw := range hwmons {
+		if readSysString(hw+"/name") != "coretemp" {
+			continue
+		}
+		labels, _ := filepath.Glob(hw + "/temp*_label")
+		pkg := 0
+		cores := map[int]float64{}
+		for _, l := range labels {
+			label := strings.ToLower(readSysString(l))
+			temp, ok := readMilliCelsius(strings.TrimSuffix(l, "_label") + "_input")
+			if !ok {
+				continue
+			}
+			var n int
+			if _, err := fmt.Sscanf(label, "package id %d", &n); err == nil {
+				pkg = n
+			} else if _, err := fmt.Sscanf(label, "core %d", &n); err == nil {
+				cores[n] = temp
+			}
+		}
+		for core, temp := range cores {
+			temps[CoreKey{Package: pkg, Core: core}] = temp
+		}
+	}
+	return temps
+}
+
+type DriveTemp struct {
+	Label    string
+	Current  float64
+	Warning  float64
+	Critical float64
+}
+
+// GetDriveTemps maps the nvme and drivetemp hwmon sensors back to the
+// /sys/block device that owns them, keyed by block device name.
+func GetDriveTemps() map[string][]DriveTemp {
+	result := map[string][]DriveTemp{}
+
+	// Resolve every block device to its real sysfs path so hwmon parents can be matched
+	blockPaths := map[string]string{}
+	if blocks, err := os.ReadDir("/sys/block/"); err == nil {
+		for _, b := range blocks {
+			if p, err := filepath.EvalSymlinks("/sys/block/" + b.Name()); err == nil {
+				blockPaths[b.Name()] = p
+			}
+		}
+	}
+
+	hwmonBase := "/sys/class/hwmon/"
+	hwmons, err := os.ReadDir(hwmonBase)
+	if err != nil {
+		return result
+	}
+	for _, hw := range hwmons {
+		hwPath := hwmonBase + hw.Name() + "/"
+		nBytes, err := os.ReadFile(hwPath + "name")
+		if err != nil {
+			continue
+		}
+		name := strings.TrimSpace(string(nBytes))
+		if name != "nvme" && name != "drivetemp" {
+			continue
+		}
+		// nvme hwmons hang off the controller, drivetemp off the SCSI device
+		devPath, err := filepath.EvalSymlinks(hwPath + "device")
+		if err != nil {
+			continue
+		}
+		var temps []DriveTemp
+		for i := 1; i <= 10; i++ {
+			cur, ok := readMilliCelsius(fmt.Sprintf("%stemp%d_input", hwPath, i))
+			if !ok {
+				continue
+			}
+			label := fmt.Sprintf("Temp%d", i)
+			if lBytes, err := os.ReadFile(fmt.Sprintf("%stemp%d_label", hwPath, i)); err == nil {
+				label = strings.TrimSpace(string(lBytes))
+			}
+			warn, _ := readMilliCelsius(fmt.Sprintf("%stemp%d_max", hwPath, i))
+			crit, _ := readMilliCelsius(fmt.Sprintf("%stemp%d_crit", hwPath, i))
+			temps = append(temps, DriveTemp{Label: label, Current: cur, Warning: warn, Critical: crit})
+		}
+		if len(temps) == 0 {
+			continue
+		}
+		for dev, p := range blockPaths {
+			if strings.HasPrefix(p, devPath+"/") {
+				result[dev] = temps
+			}
+		}
+	}
+	return result
+}
+
+func readMilliCelsius(path string) (float64, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v / 1000.0, true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCPUPackageTemps(t *testing.T) {
+	tests := []struct {
+		name    string
+		sensors map[string]float64
+		want    []LabeledTemp
+	}{
+		{
+			name:    "single socket AMD",
+			sensors: map[string]float64{"Tccd2": 61, "Tctl": 70, "Tccd1": 64, "Core 0": 50},
+			want:    []LabeledTemp{{"Package (Tctl)", 70}, {"CCD 1", 64}, {"CCD 2", 61}},
+		},
+		{
+			name: "two socket EPYC keeps both sockets",
+			sensors: map[string]float64{
+				"Socket 0 Tctl": 55, "Socket 0 Tccd1": 50,
+				"Socket 1 Tctl": 72, "Socket 1 Tccd1": 68, "Socket 1 Tccd10": 66,
+			},
+			want: []LabeledTemp{
+				{"Socket 0 Package (Tctl)", 55}, {"Socket 0 CCD 1", 50},
+				{"Socket 1 Package (Tctl)", 72}, {"Socket 1 CCD 1", 68}, {"Socket 1 CCD 10", 66},
+			},
+		},
+		{
+			name:    "two socket Intel",
+			sensors: map[string]float64{"Socket 1 Package id 1": 60, "Socket 0 Package id 0": 48, "Socket 0 Core 0": 45},
+			want:    []LabeledTemp{{"Socket 0 Package", 48}, {"Socket 1 Package", 60}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CPUPackageTemps(tt.sensors); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitSocketPrefix(t *testing.T) {
+	tests := []struct {
+		key    string
+		socket int
+		label  string
+		ok     bool
+	}{
+		{"Socket 1 Tctl", 1, "Tctl", true},
+		{"Node 5 Tccd1", 5, "Tccd1", true},
+		{"Tctl", 0, "Tctl", false},
+		{"Socket x Tctl", 0, "Socket x Tctl", false},
+	}
+	for _, tt := range tests {
+		socket, label, ok := splitSocketPrefix(tt.key)
+		if socket != tt.socket || label != tt.label || ok != tt.ok {
+			t.Errorf("splitSocketPrefix(%q) = %d, %q, %v, want %d, %q, %v", tt.key, socket, label, ok, tt.socket, tt.label, tt.ok)
+		}
+	}
+}
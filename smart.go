@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"fyne.io/fyne/v2"
+)
+
+const (
+	// _IOWR('N', 0x41, struct nvme_admin_cmd)
+	nvmeIoctlAdminCmd   = 0xC0484E41
+	nvmeAdminGetLogPage = 0x02
+	nvmeLogSMART        = 0x02
+
+	hdioDriveCmd        = 0x031F
+	ataCmdSMART         = 0xB0
+	ataCmdCheckPower    = 0xE5
+	ataSMARTReadValues  = 0xD0
+	ataSMARTReadThresh  = 0xD1
+	ataSectorSize       = 512
+	smartLogSize        = 512
+	smartCacheLifetime  = 5 * time.Minute
+	ataSMARTAttrCount   = 30
+	ataSMARTAttrSize    = 12
+	ataSMARTAttrsOffset = 2
+)
+
+// Mirrors struct nvme_passthru_cmd from linux/nvme_ioctl.h
+type nvmeAdminCmd struct {
+	Opcode      uint8
+	Flags       uint8
+	Rsvd1       uint16
+	NSID        uint32
+	Cdw2        uint32
+	Cdw3        uint32
+	Metadata    uint64
+	Addr        uint64
+	MetadataLen uint32
+	DataLen     uint32
+	Cdw10       uint32
+	Cdw11       uint32
+	Cdw12       uint32
+	Cdw13       uint32
+	Cdw14       uint32
+	Cdw15       uint32
+	TimeoutMs   uint32
+	Result      uint32
+}
+
+type SMARTAttribute struct {
+	ID        byte
+	Name      string
+	Value     byte
+	Worst     byte
+	Threshold byte
+	Raw       uint64
+	Failing   bool
+}
+
+type DriveHealth struct {
+	Protocol           string
+	Passed             bool
+	PercentUsed        int // -1 when the drive doesn't report wear
+	Temperature        int // °C, 0 when not reported
+	PowerOnHours       uint64
+	PowerCycles        uint64
+	UnsafeShutdowns    uint64
+	MediaErrors        uint64
+	ReallocatedSectors uint64
+	Failing            []string
+	Attributes         []SMARTAttribute
+}
+
+var ataAttributeNames = map[byte]string{
+	1:   "Raw Read Error Rate",
+	5:   "Reallocated Sectors",
+	9:   "Power-On Hours",
+	10:  "Spin Retry Count",
+	12:  "Power Cycle Count",
+	169: "Remaining Life",
+	173: "Wear Leveling Count",
+	174: "Unexpected Power Loss",
+	177: "Wear Leveling Count",
+	184: "End-to-End Error",
+	187: "Reported Uncorrectable",
+	188: "Command Timeout",
+	192: "Power-Off Retract Count",
+	194: "Temperature",
+	196: "Reallocation Events",
+	197: "Current Pending Sectors",
+	198: "Offline Uncorrectable",
+	199: "UDMA CRC Errors",
+	202: "Percent Lifetime Remaining",
+	231: "SSD Life Left",
+	233: "Media Wearout Indicator",
+}
+
+var nvmeCriticalWarnings = []string{
+	"Available spare below threshold",
+	"Temperature outside limits",
+	"NVM subsystem reliability degraded",
+	"Media placed in read-only mode",
+	"Volatile memory backup failed",
+	"Persistent memory region read-only",
+}
+
+type cachedHealth struct {
+	health  DriveHealth
+	err     error
+	fetched time.Time
+}
+
+// SMART reads are slow and can wake sleeping disks, so results are kept for a
+// while. Both maps are only touched on the Fyne goroutine.
+var healthCache = map[string]cachedHealth{}
+var healthPending = map[string]bool{}
+
+var errHealthPending = errors.New("SMART data not read yet")
+var errSMARTUnsupported = errors.New("SMART not supported on this transport")
+
+// GetDriveHealth returns the last SMART result for d and, when that is missing
+// or stale, reads it again in the background so the ioctls (and a disk
+// spinning up to answer them) never block the UI. The next refresh after the
+// read finishes shows the new data. Must be called on the Fyne goroutine.
+func GetDriveHealth(d Drive) (DriveHealth, error) {
+	c, cached := healthCache[d.Name]
+	if (!cached || time.Since(c.fetched) >= smartCacheLifetime) && !healthPending[d.Name] {
+		healthPending[d.Name] = true
+		go func() {
+			health, err := readDriveHealth(d)
+			fyne.Do(func() {
+				delete(healthPending, d.Name)
+				if errors.Is(err, errDriveAsleep) {
+					// Keep showing the last result rather than spinning the drive up
+					if prev, ok := healthCache[d.Name]; ok {
+						health, err = prev.health, prev.err
+					}
+				}
+				healthCache[d.Name] = cachedHealth{health: health, err: err, fetched: time.Now()}
+			})
+		}()
+	}
+	if !cached {
+		return DriveHealth{}, errHealthPending
+	}
+	return c.health, c.err
+}
+
+var errDriveAsleep = errors.New("drive is in standby")
+
+// readDriveHealth picks the passthrough from the detected transport. HDIO_DRIVE_CMD
+// is only implemented by libata, so USB bridges, virtio, MMC, md and zram are skipped.
+func readDriveHealth(d Drive) (DriveHealth, error) {
+	switch {
+	case strings.HasPrefix(d.Transport, "NVMe"):
+		return readNVMeHealth(d.Name)
+	case d.Transport == "SATA":
+		return readATAHealth(d.Name)
+	}
+	return DriveHealth{}, errSMARTUnsupported
+}
+
+func readNVMeHealth(dev string) (DriveHealth, error) {
+	f, err := os.Open("/dev/" + dev)
+	if err != nil {
+		return DriveHealth{}, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, smartLogSize)
+	cmd := nvmeAdminCmd{
+		Opcode:  nvmeAdminGetLogPage,
+		NSID:    0xFFFFFFFF,
+		Addr:    uint64(uintptr(unsafe.Pointer(&buf[0]))),
+		DataLen: smartLogSize,
+		// Number of dwords minus one in the upper half, log identifier in the lower
+		Cdw10: uint32(smartLogSize/4-1)<<16 | nvmeLogSMART,
+	}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), nvmeIoctlAdminCmd, uintptr(unsafe.Pointer(&cmd)))
+	runtime.KeepAlive(buf)
+	if errno != 0 {
+		return DriveHealth{}, errno
+	}
+	return ParseNVMeSMARTLog(buf)
+}
+
+func readATAHealth(dev string) (DriveHealth, error) {
+	f, err := os.Open("/dev/" + dev)
+	if err != nil {
+		return DriveHealth{}, err
+	}
+	defer f.Close()
+
+	// CHECK POWER MODE returns 0x00 in the sector count when the drive is spun down
+	power := make([]byte, 4)
+	power[0] = ataCmdCheckPower
+	if err := ataDriveCmd(f, power); err == nil && power[2] == 0x00 {
+		return DriveHealth{}, errDriveAsleep
+	}
+
+	values := make([]byte, 4+ataSectorSize)
+	values[0], values[2], values[3] = ataCmdSMART, ataSMARTReadValues, 1
+	if err := ataDriveCmd(f, values); err != nil {
+		return DriveHealth{}, err
+	}
+	thresholds := make([]byte, 4+ataSectorSize)
+	thresholds[0], thresholds[2], thresholds[3] = ataCmdSMART, ataSMARTReadThresh, 1
+	if err := ataDriveCmd(f, thresholds); err != nil {
+		// Thresholds are optional; without them nothing is flagged as failing
+		thresholds = nil
+	}
+	if thresholds != nil {
+		thresholds = thresholds[4:]
+	}
+	return ParseATASMART(values[4:], thresholds)
+}
+
+// ataDriveCmd issues HDIO_DRIVE_CMD; args holds the 4 command bytes followed by any data
+func ataDriveCmd(f *os.File, args []byte) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), hdioDriveCmd, uintptr(unsafe.Pointer(&args[0])))
+	runtime.KeepAlive(args)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// ParseNVMeSMARTLog decodes the 512 byte SMART / Health Information log page (LID 02h)
+func ParseNVMeSMARTLog(data []byte) (DriveHealth, error) {
+	if len(data) < smartLogSize {
+		return DriveHealth{}, fmt.Errorf("nvme smart log too short: %d bytes", len(data))
+	}
+	// 128-bit counters; the upper half is never reached in practice
+	counter := func(offset int) uint64 {
+		return binary.LittleEndian.Uint64(data[offset : offset+8])
+	}
+	h := DriveHealth{
+		Protocol:        "NVMe",
+		PercentUsed:     int(data[5]),
+		PowerCycles:     counter(112),
+		PowerOnHours:    counter(128),
+		UnsafeShutdowns: counter(144),
+		MediaErrors:     counter(160),
+	}
+	// Composite temperature in Kelvin
+	if kelvin := int(binary.LittleEndian.Uint16(data[1:3])); kelvin > 0 {
+		h.Temperature = kelvin - 273
+	}
+	critical := data[0]
+	for bit, msg := range nvmeCriticalWarnings {
+		if critical&(1<<bit) != 0 {
+			h.Failing = append(h.Failing, msg)
+		}
+	}
+	if h.PercentUsed >= 100 {
+		h.Failing = append(h.Failing, fmt.Sprintf("Rated endurance used (%d%%)", h.PercentUsed))
+	}
+	if h.MediaErrors > 0 {
+		h.Failing = append(h.Failing, fmt.Sprintf("Media errors: %d", h.MediaErrors))
+	}
+	h.Passed = critical == 0 && h.PercentUsed < 100
+	return h, nil
+}
+
+// ParseATASMART decodes the SMART READ DATA sector and, if present, the
+// matching SMART READ THRESHOLDS sector
+func ParseATASMART(values, thresholds []byte) (DriveHealth, error) {
+	if len(values) < ataSectorSize {
+		return DriveHealth{}, fmt.Errorf("ata smart data too short: %d bytes", len(values))
+	}
+	threshByID := map[byte]byte{}
+	if len(thresholds) >= ataSectorSize {
+		for i := 0; i < ataSMARTAttrCount; i++ {
+			off := ataSMARTAttrsOffset + i*ataSMARTAttrSize
+			if id := thresholds[off]; id != 0 {
+				threshByID[id] = thresholds[off+1]
+			}
+		}
+	}
+
+	h := DriveHealth{Protocol: "ATA", Passed: true, PercentUsed: -1}
+	for i := 0; i < ataSMARTAttrCount; i++ {
+		off := ataSMARTAttrsOffset + i*ataSMARTAttrSize
+		id := values[off]
+		if id == 0 {
+			continue
+		}
+		// 48-bit little-endian raw value
+		var raw uint64
+		for b := 5; b >= 0; b-- {
+			raw = raw<<8 | uint64(values[off+5+b])
+		}
+		attr := SMARTAttribute{
+			ID:        id,
+			Name:      ataAttributeNames[id],
+			Value:     values[off+3],
+			Worst:     values[off+4],
+			Threshold: threshByID[id],
+			Raw:       raw,
+		}
+		if attr.Name == "" {
+			attr.Name = fmt.Sprintf("Attribute %d", id)
+		}
+		attr.Failing = attr.Threshold > 0 && attr.Value <= attr.Threshold
+		if attr.Failing {
+			h.Passed = false
+			h.Failing = append(h.Failing, fmt.Sprintf("%s (%d <= %d)", attr.Name, attr.Value, attr.Threshold))
+		}
+
+		switch id {
+		case 5:
+			h.ReallocatedSectors = raw
+			if raw > 0 && !attr.Failing {
+				h.Failing = append(h.Failing, fmt.Sprintf("Reallocated sectors: %d", raw))
+			}
+		case 9:
+			// Some vendors pack minutes into the upper bytes
+			h.PowerOnHours = raw & 0xFFFFFFFF
+		case 12:
+			h.PowerCycles = raw
+		case 194:
+			// Current temperature in the low byte, min/max above it on many drives
+			h.Temperature = int(raw & 0xFF)
+		case 174, 192:
+			h.UnsafeShutdowns = raw
+		case 187, 198:
+			h.MediaErrors += raw
+		case 169, 177, 202, 231, 233:
+			// Normalized value counts down from 100 as the flash wears
+			if attr.Value <= 100 {
+				h.PercentUsed = 100 - int(attr.Value)
+			}
+		}
+		h.Attributes = append(h.Attributes, attr)
+	}
+	return h, nil
+}
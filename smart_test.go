@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+// The testdata pages follow the NVMe SMART / Health Information log (LID 02h)
+// and ATA SMART READ DATA / READ THRESHOLDS sector layouts byte for byte.
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseNVMeSMARTLog(t *testing.T) {
+	tests := []struct {
+		fixture         string
+		passed          bool
+		temperature     int
+		percentUsed     int
+		powerOnHours    uint64
+		unsafeShutdowns uint64
+		failing         []string
+	}{
+		{
+			fixture:         "nvme_smart_healthy.bin",
+			passed:          true,
+			temperature:     38,
+			percentUsed:     3,
+			powerOnHours:    8760,
+			unsafeShutdowns: 17,
+		},
+		{
+			fixture:         "nvme_smart_worn.bin",
+			passed:          false,
+			temperature:     85,
+			percentUsed:     104,
+			powerOnHours:    40000,
+			unsafeShutdowns: 90,
+			failing: []string{
+				"Available spare below threshold",
+				"NVM subsystem reliability degraded",
+				"Rated endurance used (104%)",
+				"Media errors: 3",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			h, err := ParseNVMeSMARTLog(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", h.Passed, tt.passed)
+			}
+			if h.Temperature != tt.temperature {
+				t.Errorf("Temperature = %d, want %d", h.Temperature, tt.temperature)
+			}
+			if h.PercentUsed != tt.percentUsed {
+				t.Errorf("PercentUsed = %d, want %d", h.PercentUsed, tt.percentUsed)
+			}
+			if h.PowerOnHours != tt.powerOnHours {
+				t.Errorf("PowerOnHours = %d, want %d", h.PowerOnHours, tt.powerOnHours)
+			}
+			if h.UnsafeShutdowns != tt.unsafeShutdowns {
+				t.Errorf("UnsafeShutdowns = %d, want %d", h.UnsafeShutdowns, tt.unsafeShutdowns)
+			}
+			if !reflect.DeepEqual(h.Failing, tt.failing) {
+				t.Errorf("Failing = %q, want %q", h.Failing, tt.failing)
+			}
+		})
+	}
+}
+
+func TestParseNVMeSMARTLogShort(t *testing.T) {
+	if _, err := ParseNVMeSMARTLog(make([]byte, 100)); err == nil {
+		t.Error("expected an error for a truncated log page")
+	}
+}
+
+func TestParseATASMART(t *testing.T) {
+	tests := []struct {
+		values, thresholds string
+		passed             bool
+		temperature        int
+		percentUsed        int
+		powerOnHours       uint64
+		reallocated        uint64
+		failing            []string
+	}{
+		{
+			values:       "ata_smart_values.bin",
+			thresholds:   "ata_smart_thresholds.bin",
+			passed:       true,
+			temperature:  34,
+			percentUsed:  8,
+			powerOnHours: 20345,
+		},
+		{
+			values:       "ata_smart_failing_values.bin",
+			thresholds:   "ata_smart_failing_thresholds.bin",
+			passed:       false,
+			temperature:  41,
+			percentUsed:  -1,
+			powerOnHours: 61000,
+			reallocated:  1850,
+			failing:      []string{"Reallocated Sectors (5 <= 10)"},
+		},
+		{
+			// Without thresholds nothing can be judged failing, only reported
+			values:       "ata_smart_failing_values.bin",
+			passed:       true,
+			temperature:  41,
+			percentUsed:  -1,
+			powerOnHours: 61000,
+			reallocated:  1850,
+			failing:      []string{"Reallocated sectors: 1850"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.values+"+"+tt.thresholds, func(t *testing.T) {
+			var thresholds []byte
+			if tt.thresholds != "" {
+				thresholds = readFixture(t, tt.thresholds)
+			}
+			h, err := ParseATASMART(readFixture(t, tt.values), thresholds)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if h.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v", h.Passed, tt.passed)
+			}
+			if h.Temperature != tt.temperature {
+				t.Errorf("Temperature = %d, want %d", h.Temperature, tt.temperature)
+			}
+			if h.PercentUsed != tt.percentUsed {
+				t.Errorf("PercentUsed = %d, want %d", h.PercentUsed, tt.percentUsed)
+			}
+			if h.PowerOnHours != tt.powerOnHours {
+				t.Errorf("PowerOnHours = %d, want %d", h.PowerOnHours, tt.powerOnHours)
+			}
+			if h.ReallocatedSectors != tt.reallocated {
+				t.Errorf("ReallocatedSectors = %d, want %d", h.ReallocatedSectors, tt.reallocated)
+			}
+			if !reflect.DeepEqual(h.Failing, tt.failing) {
+				t.Errorf("Failing = %q, want %q", h.Failing, tt.failing)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SPDModule is what we take from a DIMM's SPD EEPROM, exposed by the ee1004
+// (DDR4), spd5118 (DDR5) or legacy eeprom (DDR3) i2c drivers
+type SPDModule struct {
+	Device             string // i2c device, e.g. 0-0050
+	MemoryType         string
+	ModuleManufacturer string
+	DRAMManufacturer   string
+	PartNumber         string
+}
+
+// Byte offsets of the manufacturer IDs and part number per SPD revision
+type spdLayout struct {
+	memoryType string
+	module     int
+	dram       int
+	partStart  int
+	partEnd    int
+}
+
+// size is how much of the EEPROM a dump must hold for every field we read
+func (l spdLayout) size() int {
+	return max(l.partEnd, l.module+2, l.dram+2)
+}
+
+var spdLayouts = map[byte]spdLayout{
+	0x0B: {"DDR3", 117, 148, 128, 146},
+	0x0C: {"DDR4", 320, 350, 329, 349},
+	0x12: {"DDR5", 512, 552, 521, 551},
+}
+
+// GetSPDModules reads every SPD EEPROM the kernel exposes. The drivers are
+// only bound when the i2c/SMBus controller is loaded, so this is often empty.
+func GetSPDModules() []SPDModule {
+	var modules []SPDModule
+	for _, driver := range []string{"spd5118", "ee1004", "eeprom"} {
+		paths, _ := filepath.Glob("/sys/bus/i2c/drivers/" + driver + "/*/eeprom")
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			if m, ok := ParseSPD(data); ok {
+				m.Device = filepath.Base(filepath.Dir(path))
+				modules = append(modules, m)
+			}
+		}
+	}
+	return modules
+}
+
+// ParseSPD decodes the manufacturer IDs and part number from a raw SPD dump
+func ParseSPD(data []byte) (SPDModule, bool) {
+	if len(data) < 3 {
+		return SPDModule{}, false
+	}
+	layout, ok := spdLayouts[data[2]]
+	if !ok || len(data) < layout.size() {
+		return SPDModule{}, false
+	}
+	return SPDModule{
+		MemoryType:         layout.memoryType,
+		ModuleManufacturer: spdManufacturer(data[layout.module], data[layout.module+1]),
+		DRAMManufacturer:   spdManufacturer(data[layout.dram], data[layout.dram+1]),
+		PartNumber:         strings.TrimSpace(strings.Trim(string(data[layout.partStart:layout.partEnd]), "\x00")),
+	}, true
+}
+
+func spdManufacturer(continuation, id byte) string {
+	if id == 0 || id == 0xFF {
+		return ""
+	}
+	if name := JEP106Name(continuation, id); name != "" {
+		return name
+	}
+	return "Unknown"
+}
@@ -0,0 +1,77 @@
+package main
+
+import "testing"
+
+// spdDump builds an SPD image of the given length with the DRAM type byte and
+// the fields ParseSPD reads filled in at the offsets for that type
+func spdDump(memType byte, length int, module, dram [2]byte, part string) []byte {
+	data := make([]byte, length)
+	data[2] = memType
+	l := spdLayouts[memType]
+	put := func(offset int, b ...byte) {
+		for i, v := range b {
+			if offset+i < len(data) {
+				data[offset+i] = v
+			}
+		}
+	}
+	put(l.module, module[0], module[1])
+	put(l.dram, dram[0], dram[1])
+	put(l.partStart, []byte(part)...)
+	return data
+}
+
+func TestParseSPD(t *testing.T) {
+	samsung := [2]byte{0x80, 0xCE}
+	corsair := [2]byte{0x02, 0x9E}
+	kingston := [2]byte{0x01, 0x98}
+	tests := []struct {
+		name string
+		data []byte
+		want SPDModule
+		ok   bool
+	}{
+		{
+			name: "DDR3",
+			data: spdDump(0x0B, 256, kingston, samsung, "KHX1600C10D3/8G"),
+			want: SPDModule{MemoryType: "DDR3", ModuleManufacturer: "Kingston", DRAMManufacturer: "Samsung", PartNumber: "KHX1600C10D3/8G"},
+			ok:   true,
+		},
+		{
+			name: "DDR4",
+			data: spdDump(0x0C, 512, corsair, samsung, "CMK32GX4M2B3200C16  "),
+			want: SPDModule{MemoryType: "DDR4", ModuleManufacturer: "Corsair", DRAMManufacturer: "Samsung", PartNumber: "CMK32GX4M2B3200C16"},
+			ok:   true,
+		},
+		{
+			name: "DDR5",
+			data: spdDump(0x12, 1024, kingston, samsung, "KF560C40-16"),
+			want: SPDModule{MemoryType: "DDR5", ModuleManufacturer: "Kingston", DRAMManufacturer: "Samsung", PartNumber: "KF560C40-16"},
+			ok:   true,
+		},
+		{
+			name: "unprogrammed manufacturer bytes",
+			data: spdDump(0x0C, 512, [2]byte{0xFF, 0xFF}, [2]byte{}, ""),
+			want: SPDModule{MemoryType: "DDR4"},
+			ok:   true,
+		},
+		// Long enough for the part number but not the DRAM manufacturer behind it
+		{name: "DDR3 truncated before DRAM ID", data: spdDump(0x0B, 147, kingston, samsung, "X")},
+		{name: "DDR5 truncated before DRAM ID", data: spdDump(0x12, 552, kingston, samsung, "X")},
+		{name: "DDR4 truncated", data: spdDump(0x0C, 128, corsair, samsung, "X")},
+		{name: "shorter than the type byte", data: spdDump(0x0C, 512, corsair, samsung, "X")[:2]},
+		{name: "unsupported type byte", data: []byte{0x23, 0x10, 0x07}},
+		{name: "empty", data: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseSPD(tt.data)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+// Storage is a snapshot of the drive layout. It is only rebuilt when the
+// hotplug watcher reports a block device or mount change, not every tick.
+type Storage struct {
+	Drives []Drive
+	Stacks map[string]*BlockNode
+	Mounts mountTable
+}
+
+func ScanStorage() *Storage {
+	s := &Storage{
+		Stacks: map[string]*BlockNode{},
+		Mounts: readMountInfo(),
+	}
+	for _, dev := range GetDrives() {
+		s.Drives = append(s.Drives, GetDriveInfo(dev))
+		s.Stacks[dev] = BuildBlockStack(dev)
+	}
+	return s
+}
+
+func (s *Storage) Names() []string {
+	names := make([]string, 0, len(s.Drives))
+	for _, d := range s.Drives {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+func (s *Storage) Drive(name string) (Drive, bool) {
+	for _, d := range s.Drives {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Drive{}, false
+}
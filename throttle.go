@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ThrottleCounter struct {
+	Name  string // "Package 0" or "Core 3"
+	Count uint64
+	// Increase since jayinsights started
+	SessionCount uint64
+}
+
+// ThrottleMonitor remembers the counters from startup and from the last
+// refresh so throttling that happened between refreshes isn't lost
+type ThrottleMonitor struct {
+	baseline map[string]uint64
+	last     map[string]uint64
+}
+
+func NewThrottleMonitor() *ThrottleMonitor {
+	return &ThrottleMonitor{baseline: map[string]uint64{}, last: map[string]uint64{}}
+}
+
+// Update reads the counters and returns them along with one event per
+// counter that went up since the previous call
+func (m *ThrottleMonitor) Update(topo CPUTopology) ([]ThrottleCounter, []string) {
+	counters := readThrottleCounters(topo)
+	var events []string
+	for i, c := range counters {
+		base, seen := m.baseline[c.Name]
+		if !seen {
+			m.baseline[c.Name] = c.Count
+			base = c.Count
+		} else if c.Count > m.last[c.Name] {
+			events = append(events, fmt.Sprintf("%s throttled %d time(s)", c.Name, c.Count-m.last[c.Name]))
+		}
+		if c.Count >= base {
+			counters[i].SessionCount = c.Count - base
+		}
+		m.last[c.Name] = c.Count
+	}
+	return counters, events
+}
+
+// readThrottleCounters reads thermal_throttle once per package and once per
+// core; sibling threads report the same counters
+func readThrottleCounters(topo CPUTopology) []ThrottleCounter {
+	var counters []ThrottleCounter
+	for _, s := range topo.Sockets {
+		if len(s.Cores) == 0 {
+			continue
+		}
+		pkgPath := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/thermal_throttle/package_throttle_count", s.Cores[0].Threads[0])
+		if v, err := strconv.ParseUint(readSysString(pkgPath), 10, 64); err == nil {
+			counters = append(counters, ThrottleCounter{Name: fmt.Sprintf("Package %d", s.ID), Count: v})
+		}
+		for _, c := range s.Cores {
+			corePath := fmt.Sprintf("/sys/devices/system/cpu/cpu%d/thermal_throttle/core_throttle_count", c.Threads[0])
+			if v, err := strconv.ParseUint(readSysString(corePath), 10, 64); err == nil {
+				name := fmt.Sprintf("Core %d", c.ID)
+				if len(topo.Sockets) > 1 {
+					name = fmt.Sprintf("Socket %d Core %d", s.ID, c.ID)
+				}
+				counters = append(counters, ThrottleCounter{Name: name, Count: v})
+			}
+		}
+	}
+	return counters
+}
+
+// GetGPUThrottleReasons returns the active throttle reasons per DRM card.
+// Only Intel exposes these in sysfs: i915 under gt/gt*/throttle_reason_*,
+// xe under device/tile*/gt*/freq0/throttle/reason_*.
+func GetGPUThrottleReasons() map[string][]string {
+	result := map[string][]string{}
+	patterns := []struct {
+		glob, prefix string
+	}{
+		{"/sys/class/drm/card[0-9]*/gt/gt*/throttle_reason_*", "throttle_reason_"},
+		{"/sys/class/drm/card[0-9]*/device/tile*/gt*/freq0/throttle/reason_*", "reason_"},
+	}
+	for _, p := range patterns {
+		files, _ := filepath.Glob(p.glob)
+		for _, f := range files {
+			reason := strings.TrimPrefix(filepath.Base(f), p.prefix)
+			// "status" is the summary bit, the others say why
+			if reason == "status" || readSysString(f) != "1" {
+				continue
+			}
+			card := strings.Split(strings.TrimPrefix(f, "/sys/class/drm/"), "/")[0]
+			result[card] = append(result[card], reason)
+		}
+	}
+	for card := range result {
+		sort.Strings(result[card])
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type CPUTopology struct {
+	Sockets []CPUSocket
+	Caches  []CPUCache
+	// NUMA node number to the logical CPUs it holds
+	NUMANodes map[int][]int
+	Threads   int
+}
+
+type CPUSocket struct {
+	ID    int
+	Cores []CPUCore
+}
+
+type CPUCore struct {
+	ID      int
+	Kind    string // "P" or "E" on hybrid parts, empty otherwise
+	Threads []int
+}
+
+type CPUCache struct {
+	Level      int
+	Type       string // Data, Instruction or Unified
+	SizeKB     int
+	SharedCPUs []int
+}
+
+// Name is the usual short form: L1d, L1i, L2, L3
+func (c CPUCache) Name() string {
+	switch c.Type {
+	case "Data":
+		return fmt.Sprintf("L%dd", c.Level)
+	case "Instruction":
+		return fmt.Sprintf("L%di", c.Level)
+	}
+	return fmt.Sprintf("L%d", c.Level)
+}
+
+func (t CPUTopology) CoreCount() int {
+	n := 0
+	for _, s := range t.Sockets {
+		n += len(s.Cores)
+	}
+	return n
+}
+
+// CoreOf returns the core a logical CPU belongs to
+func (t CPUTopology) CoreOf(cpu int) (CPUSocket, CPUCore, bool) {
+	for _, s := range t.Sockets {
+		for _, c := range s.Cores {
+			for _, th := range c.Threads {
+				if th == cpu {
+					return s, c, true
+				}
+			}
+		}
+	}
+	return CPUSocket{}, CPUCore{}, false
+}
+
+// GetCPUTopology builds sockets → cores → threads from /sys/devices/system/cpu
+func GetCPUTopology() CPUTopology {
+	topo := CPUTopology{NUMANodes: map[int][]int{}}
+	cpuBase := "/sys/devices/system/cpu/"
+	online := parseCPUList(readSysString(cpuBase + "online"))
+	kinds := hybridCoreKinds()
+
+	sockets := map[int]map[int]*CPUCore{}
+	caches := map[string]CPUCache{}
+	for _, cpu := range online {
+		base := fmt.Sprintf("%scpu%d/", cpuBase, cpu)
+		pkg, err := strconv.Atoi(readSysString(base + "topology/physical_package_id"))
+		if err != nil {
+			continue
+		}
+		coreID, _ := strconv.Atoi(readSysString(base + "topology/core_id"))
+		// core_id is only unique within a die, so key by the die too
+		dieID, _ := strconv.Atoi(readSysString(base + "topology/die_id"))
+		key := dieID<<16 | coreID
+		if sockets[pkg] == nil {
+			sockets[pkg] = map[int]*CPUCore{}
+		}
+		core := sockets[pkg][key]
+		if core == nil {
+			core = &CPUCore{ID: coreID, Kind: kinds[cpu]}
+			sockets[pkg][key] = core
+		}
+		core.Threads = append(core.Threads, cpu)
+		topo.Threads++
+
+		indexes, _ := filepath.Glob(base + "cache/index*")
+		for _, idx := range indexes {
+			c := CPUCache{Type: readSysString(idx + "/type")}
+			c.Level, _ = strconv.Atoi(readSysString(idx + "/level"))
+			c.SizeKB = parseCacheSize(readSysString(idx + "/size"))
+			shared := readSysString(idx + "/shared_cpu_list")
+			c.SharedCPUs = parseCPUList(shared)
+			// The same cache shows up under every CPU sharing it
+			caches[fmt.Sprintf("%d-%s-%s", c.Level, c.Type, shared)] = c
+		}
+	}
+
+	for pkg, cores := range sockets {
+		s := CPUSocket{ID: pkg}
+		for _, c := range cores {
+			sort.Ints(c.Threads)
+			s.Cores = append(s.Cores, *c)
+		}
+		sort.Slice(s.Cores, func(i, j int) bool { return s.Cores[i].Threads[0] < s.Cores[j].Threads[0] })
+		topo.Sockets = append(topo.Sockets, s)
+	}
+	sort.Slice(topo.Sockets, func(i, j int) bool { return topo.Sockets[i].ID < topo.Sockets[j].ID })
+
+	for _, c := range caches {
+		topo.Caches = append(topo.Caches, c)
+	}
+	sort.Slice(topo.Caches, func(i, j int) bool {
+		if topo.Caches[i].Level != topo.Caches[j].Level {
+			return topo.Caches[i].Level < topo.Caches[j].Level
+		}
+		if topo.Caches[i].Type != topo.Caches[j].Type {
+			return topo.Caches[i].Type < topo.Caches[j].Type
+		}
+		return fmt.Sprint(topo.Caches[i].SharedCPUs) < fmt.Sprint(topo.Caches[j].SharedCPUs)
+	})
+
+	nodes, _ := filepath.Glob("/sys/devices/system/node/node[0-9]*")
+	for _, n := range nodes {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(n), "node"))
+		if err != nil {
+			continue
+		}
+		topo.NUMANodes[id] = parseCPUList(readSysString(n + "/cpulist"))
+	}
+	return topo
+}
+
+// hybridCoreKinds maps CPUs to P/E using the Intel hybrid PMU device lists,
+// falling back to cpu_capacity on asymmetric ARM systems
+func hybridCoreKinds() map[int]string {
+	kinds := map[int]string{}
+	pCores := parseCPUList(readSysString("/sys/devices/cpu_core/cpus"))
+	eCores := parseCPUList(readSysString("/sys/devices/cpu_atom/cpus"))
+	if len(pCores) > 0 && len(eCores) > 0 {
+		for _, c := range pCores {
+			kinds[c] = "P"
+		}
+		for _, c := range eCores {
+			kinds[c] = "E"
+		}
+		return kinds
+	}
+
+	capacities := map[int]int{}
+	maxCapacity := 0
+	files, _ := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*/cpu_capacity")
+	for _, f := range files {
+		cpu, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(filepath.Dir(f)), "cpu"))
+		if err != nil {
+			continue
+		}
+		capacity, err := strconv.Atoi(readSysString(f))
+		if err != nil {
+			continue
+		}
+		capacities[cpu] = capacity
+		if capacity > maxCapacity {
+			maxCapacity = capacity
+		}
+	}
+	asymmetric := false
+	for _, c := range capacities {
+		if c != maxCapacity {
+			asymmetric = true
+		}
+	}
+	if !asymmetric {
+		return kinds
+	}
+	for cpu, c := range capacities {
+		if c == maxCapacity {
+			kinds[cpu] = "P"
+		} else {
+			kinds[cpu] = "E"
+		}
+	}
+	return kinds
+}
+
+// parseCPUList expands the kernel's "0-3,8,10-11" list format
+func parseCPUList(s string) []int {
+	var cpus []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				continue
+			}
+		}
+		for c := start; c <= end; c++ {
+			cpus = append(cpus, c)
+		}
+	}
+	return cpus
+}
+
+// parseCacheSize reads sysfs cache sizes such as "48K" or "30M" into KiB
+func parseCacheSize(s string) int {
+	mult := 1
+	switch {
+	case strings.HasSuffix(s, "K"):
+		s = strings.TrimSuffix(s, "K")
+	case strings.HasSuffix(s, "M"):
+		s, mult = strings.TrimSuffix(s, "M"), 1024
+	}
+	v, _ := strconv.Atoi(s)
+	return v * mult
+}